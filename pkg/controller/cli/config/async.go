@@ -0,0 +1,66 @@
+package config
+
+import (
+	"log/slog"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/nounify/pkg/usecase"
+	"github.com/urfave/cli/v2"
+)
+
+type Async struct {
+	enabled   bool
+	queueSize int
+	workers   int
+}
+
+func (x *Async) Flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.BoolFlag{
+			Name:        "async",
+			Usage:       "Respond 202 Accepted right after policy evaluation and deliver messages in background",
+			EnvVars:     []string{"NOUNIFY_ASYNC"},
+			Destination: &x.enabled,
+		},
+		&cli.IntFlag{
+			Name:        "async-queue-size",
+			Usage:       "Maximum number of messages waiting for delivery in async mode",
+			EnvVars:     []string{"NOUNIFY_ASYNC_QUEUE_SIZE"},
+			Destination: &x.queueSize,
+			Value:       1000,
+		},
+		&cli.IntFlag{
+			Name:        "async-workers",
+			Usage:       "Number of workers delivering messages in async mode",
+			EnvVars:     []string{"NOUNIFY_ASYNC_WORKERS"},
+			Destination: &x.workers,
+			Value:       4,
+		},
+	}
+}
+
+// Options returns usecase options for async mode. It returns no option if async mode is disabled.
+func (x *Async) Options() ([]usecase.Option, error) {
+	if !x.enabled {
+		return nil, nil
+	}
+
+	if x.queueSize < 1 {
+		return nil, goerr.New("async queue size must be greater than 0").With("size", x.queueSize)
+	}
+	if x.workers < 1 {
+		return nil, goerr.New("async workers must be greater than 0").With("workers", x.workers)
+	}
+
+	return []usecase.Option{
+		usecase.WithAsync(x.queueSize, x.workers),
+	}, nil
+}
+
+func (x *Async) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Bool("enabled", x.enabled),
+		slog.Int("queue_size", x.queueSize),
+		slog.Int("workers", x.workers),
+	)
+}
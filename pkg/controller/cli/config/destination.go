@@ -0,0 +1,264 @@
+package config
+
+import (
+	"log/slog"
+	"net/http"
+	"net/mail"
+	"time"
+
+	"github.com/m-mizutani/goerr"
+
+	"github.com/m-mizutani/nounify/pkg/domain/interfaces"
+	"github.com/m-mizutani/nounify/pkg/domain/types"
+	"github.com/m-mizutani/nounify/pkg/infra/discord"
+	"github.com/m-mizutani/nounify/pkg/infra/email"
+	"github.com/m-mizutani/nounify/pkg/infra/httpclient"
+	"github.com/m-mizutani/nounify/pkg/infra/pagerduty"
+	"github.com/m-mizutani/nounify/pkg/infra/retry"
+	"github.com/m-mizutani/nounify/pkg/infra/slackwebhook"
+	"github.com/m-mizutani/nounify/pkg/infra/teams"
+	"github.com/m-mizutani/nounify/pkg/infra/webhook"
+	"github.com/m-mizutani/nounify/pkg/usecase"
+	"github.com/urfave/cli/v2"
+)
+
+// Destinations is a set of settings for destinations other than Slack.
+type Destinations struct {
+	teamsWebhooks   cli.StringSlice
+	discordWebhooks cli.StringSlice
+	pagerDutyKeys   cli.StringSlice
+
+	mattermostWebhooks cli.StringSlice
+	rocketChatWebhooks cli.StringSlice
+
+	webhook        bool
+	webhookSecret  string
+	webhookTimeout time.Duration
+	webhookHosts   cli.StringSlice
+	webhookLocal   bool
+
+	smtpHost     string
+	smtpPort     int
+	smtpUsername string
+	smtpPassword string
+	smtpFrom     string
+	smtpStartTLS bool
+}
+
+func (x *Destinations) Flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:        "teams-webhook",
+			Usage:       "Microsoft Teams incoming webhook as <channel>=<url>. The channel is specified as channel of messages with destination teams",
+			EnvVars:     []string{"NOUNIFY_TEAMS_WEBHOOK"},
+			Destination: &x.teamsWebhooks,
+		},
+		&cli.StringSliceFlag{
+			Name:        "discord-webhook",
+			Usage:       "Discord webhook as <channel>=<url>. The channel is specified as channel of messages with destination discord",
+			EnvVars:     []string{"NOUNIFY_DISCORD_WEBHOOK"},
+			Destination: &x.discordWebhooks,
+		},
+		&cli.StringSliceFlag{
+			Name:        "pagerduty-routing-key",
+			Usage:       "PagerDuty Events API v2 routing key as <channel>=<key>. The channel is specified as channel of messages with destination pagerduty",
+			EnvVars:     []string{"NOUNIFY_PAGERDUTY_ROUTING_KEY"},
+			Destination: &x.pagerDutyKeys,
+		},
+		&cli.StringSliceFlag{
+			Name:        "mattermost-webhook",
+			Usage:       "Mattermost incoming webhook as <channel>=<url>. The channel is specified as channel of messages with destination mattermost",
+			EnvVars:     []string{"NOUNIFY_MATTERMOST_WEBHOOK"},
+			Destination: &x.mattermostWebhooks,
+		},
+		&cli.StringSliceFlag{
+			Name:        "rocketchat-webhook",
+			Usage:       "Rocket.Chat incoming webhook as <channel>=<url>. The channel is specified as channel of messages with destination rocketchat",
+			EnvVars:     []string{"NOUNIFY_ROCKETCHAT_WEBHOOK"},
+			Destination: &x.rocketChatWebhooks,
+		},
+		&cli.BoolFlag{
+			Name:        "webhook",
+			Usage:       "Enable webhook destination that sends HTTP request specified by the policy",
+			EnvVars:     []string{"NOUNIFY_WEBHOOK"},
+			Destination: &x.webhook,
+		},
+		&cli.StringFlag{
+			Name:        "webhook-secret",
+			Usage:       "Secret to sign requests of webhook destination with HMAC-SHA256",
+			EnvVars:     []string{"NOUNIFY_WEBHOOK_SECRET"},
+			Destination: &x.webhookSecret,
+		},
+		&cli.DurationFlag{
+			Name:        "webhook-timeout",
+			Usage:       "Timeout of a single request of webhook destination",
+			EnvVars:     []string{"NOUNIFY_WEBHOOK_TIMEOUT"},
+			Destination: &x.webhookTimeout,
+			Value:       httpclient.DefaultTimeout,
+		},
+		&cli.StringSliceFlag{
+			Name:        "webhook-allowed-host",
+			Usage:       "Allowed host of webhook destination URL (wildcard * is available, e.g. *.example.com). Any host is allowed if not set",
+			EnvVars:     []string{"NOUNIFY_WEBHOOK_ALLOWED_HOST"},
+			Destination: &x.webhookHosts,
+		},
+		&cli.BoolFlag{
+			Name:        "webhook-allow-local-address",
+			Usage:       "Allow webhook destination to send requests to loopback and link-local addresses",
+			EnvVars:     []string{"NOUNIFY_WEBHOOK_ALLOW_LOCAL_ADDRESS"},
+			Destination: &x.webhookLocal,
+		},
+		&cli.StringFlag{
+			Name:        "smtp-host",
+			Usage:       "SMTP server host for email destination. Email destination is disabled if not set",
+			EnvVars:     []string{"NOUNIFY_SMTP_HOST"},
+			Destination: &x.smtpHost,
+		},
+		&cli.IntFlag{
+			Name:        "smtp-port",
+			Usage:       "SMTP server port",
+			EnvVars:     []string{"NOUNIFY_SMTP_PORT"},
+			Destination: &x.smtpPort,
+			Value:       587,
+		},
+		&cli.StringFlag{
+			Name:        "smtp-username",
+			Usage:       "Username of SMTP authentication (PLAIN)",
+			EnvVars:     []string{"NOUNIFY_SMTP_USERNAME"},
+			Destination: &x.smtpUsername,
+		},
+		&cli.StringFlag{
+			Name:        "smtp-password",
+			Usage:       "Password of SMTP authentication",
+			EnvVars:     []string{"NOUNIFY_SMTP_PASSWORD"},
+			Destination: &x.smtpPassword,
+		},
+		&cli.StringFlag{
+			Name:        "smtp-from",
+			Usage:       "Sender address of email, e.g. nounify <noreply@example.com>",
+			EnvVars:     []string{"NOUNIFY_SMTP_FROM"},
+			Destination: &x.smtpFrom,
+		},
+		&cli.BoolFlag{
+			Name:        "smtp-starttls",
+			Usage:       "Require STARTTLS to send email",
+			EnvVars:     []string{"NOUNIFY_SMTP_STARTTLS"},
+			Destination: &x.smtpStartTLS,
+			Value:       true,
+		},
+	}
+}
+
+// Options returns usecase options to deliver messages to configured destinations. Notifiers retry transient errors with retryCfg.
+func (x *Destinations) Options(retryCfg retry.Config) ([]usecase.Option, error) {
+	var options []usecase.Option
+	add := func(dest types.Destination, notifier interfaces.Notifier) {
+		options = append(options, usecase.WithNotifier(dest, retry.NewNotifier(notifier, dest, retryCfg)))
+	}
+
+	if len(x.teamsWebhooks.Value()) > 0 {
+		webhooks, err := parseNamedValues("teams-webhook", x.teamsWebhooks.Value())
+		if err != nil {
+			return nil, err
+		}
+		add(types.DestinationTeams, teams.New(webhooks))
+	}
+
+	if len(x.discordWebhooks.Value()) > 0 {
+		webhooks, err := parseNamedValues("discord-webhook", x.discordWebhooks.Value())
+		if err != nil {
+			return nil, err
+		}
+		add(types.DestinationDiscord, discord.New(webhooks))
+	}
+
+	if len(x.pagerDutyKeys.Value()) > 0 {
+		keys, err := parseNamedValues("pagerduty-routing-key", x.pagerDutyKeys.Value())
+		if err != nil {
+			return nil, err
+		}
+		add(types.DestinationPagerDuty, pagerduty.New(keys))
+	}
+
+	// Mattermost and Rocket.Chat accept Slack compatible payload without Block Kit
+	for _, compat := range []struct {
+		dest     types.Destination
+		flag     string
+		webhooks *cli.StringSlice
+	}{
+		{types.DestinationMattermost, "mattermost-webhook", &x.mattermostWebhooks},
+		{types.DestinationRocketChat, "rocketchat-webhook", &x.rocketChatWebhooks},
+	} {
+		if len(compat.webhooks.Value()) == 0 {
+			continue
+		}
+		webhooks, err := parseNamedValues(compat.flag, compat.webhooks.Value())
+		if err != nil {
+			return nil, err
+		}
+		add(compat.dest, slackwebhook.New(webhooks, slackwebhook.WithLegacyAttachment()))
+	}
+
+	if x.webhook {
+		if x.webhookTimeout <= 0 {
+			return nil, goerr.New("webhook timeout must be greater than 0").With("timeout", x.webhookTimeout)
+		}
+		webhookOptions := []webhook.Option{
+			webhook.WithHTTPClient(&http.Client{Timeout: x.webhookTimeout}),
+		}
+		if x.webhookSecret != "" {
+			webhookOptions = append(webhookOptions, webhook.WithSecret(x.webhookSecret))
+		}
+		if hosts := x.webhookHosts.Value(); len(hosts) > 0 {
+			webhookOptions = append(webhookOptions, webhook.WithAllowedHosts(hosts...))
+		}
+		if x.webhookLocal {
+			webhookOptions = append(webhookOptions, webhook.WithLocalAddressAllowed())
+		}
+		add(types.DestinationWebhook, webhook.New(webhookOptions...))
+	}
+
+	if x.smtpHost != "" {
+		if x.smtpFrom == "" {
+			return nil, goerr.New("smtp-from is required for email destination")
+		}
+		if _, err := mail.ParseAddress(x.smtpFrom); err != nil {
+			return nil, goerr.Wrap(err, "invalid smtp-from").With("from", x.smtpFrom)
+		}
+
+		var emailOptions []email.Option
+		if x.smtpUsername != "" {
+			emailOptions = append(emailOptions, email.WithAuth(x.smtpUsername, x.smtpPassword))
+		}
+		if x.smtpStartTLS {
+			emailOptions = append(emailOptions, email.WithStartTLS())
+		}
+		add(types.DestinationEmail, email.New(x.smtpHost, x.smtpPort, x.smtpFrom, emailOptions...))
+	}
+
+	return options, nil
+}
+
+func (x *Destinations) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Any("teams", names(x.teamsWebhooks.Value())),
+		slog.Any("discord", names(x.discordWebhooks.Value())),
+		slog.Any("pagerduty", names(x.pagerDutyKeys.Value())),
+		slog.Any("mattermost", names(x.mattermostWebhooks.Value())),
+		slog.Any("rocketchat", names(x.rocketChatWebhooks.Value())),
+		slog.Group("webhook",
+			slog.Bool("enabled", x.webhook),
+			slog.Bool("signed", x.webhookSecret != ""),
+			slog.Duration("timeout", x.webhookTimeout),
+			slog.Any("allowed_hosts", x.webhookHosts.Value()),
+			slog.Bool("allow_local_address", x.webhookLocal),
+		),
+		slog.Group("email",
+			slog.String("host", x.smtpHost),
+			slog.Int("port", x.smtpPort),
+			slog.String("from", x.smtpFrom),
+			slog.String("username", x.smtpUsername),
+			slog.Bool("starttls", x.smtpStartTLS),
+		),
+	)
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/m-mizutani/goerr"
+)
+
+// parseNamedValues parses flag values in "<name>=<value>" format. The value is not included in errors because it may be a secret.
+func parseNamedValues(flagName string, values []string) (map[string]string, error) {
+	named := make(map[string]string, len(values))
+	for _, v := range values {
+		name, value, ok := strings.Cut(v, "=")
+		name = strings.TrimSpace(name)
+		value = strings.TrimSpace(value)
+		if !ok || name == "" || value == "" {
+			return nil, goerr.New("flag value must be <name>=<value>").With("flag", flagName).With("name", name)
+		}
+		if _, exists := named[name]; exists {
+			return nil, goerr.New("duplicated name in flag").With("flag", flagName).With("name", name)
+		}
+		named[name] = value
+	}
+	return named, nil
+}
+
+// names returns sorted names of named values for logging without secrets
+func names(values []string) []string {
+	var result []string
+	for _, v := range values {
+		name, _, _ := strings.Cut(v, "=")
+		result = append(result, strings.TrimSpace(name))
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/m-mizutani/nounify/pkg/infra/retry"
+	"github.com/urfave/cli/v2"
+)
+
+type Retry struct {
+	maxAttempts int
+	interval    time.Duration
+	maxInterval time.Duration
+	budget      time.Duration
+}
+
+func (x *Retry) Flags() []cli.Flag {
+	defaults := retry.DefaultConfig()
+
+	return []cli.Flag{
+		&cli.IntFlag{
+			Name:        "retry-max-attempts",
+			Usage:       "Maximum number of attempts to deliver a message (1 disables retry)",
+			EnvVars:     []string{"NOUNIFY_RETRY_MAX_ATTEMPTS"},
+			Destination: &x.maxAttempts,
+			Value:       defaults.MaxAttempts,
+		},
+		&cli.DurationFlag{
+			Name:        "retry-interval",
+			Usage:       "Base interval of exponential backoff",
+			EnvVars:     []string{"NOUNIFY_RETRY_INTERVAL"},
+			Destination: &x.interval,
+			Value:       defaults.Interval,
+		},
+		&cli.DurationFlag{
+			Name:        "retry-max-interval",
+			Usage:       "Maximum interval between attempts",
+			EnvVars:     []string{"NOUNIFY_RETRY_MAX_INTERVAL"},
+			Destination: &x.maxInterval,
+			Value:       defaults.MaxInterval,
+		},
+		&cli.DurationFlag{
+			Name:        "retry-budget",
+			Usage:       "Total time budget for delivering a message including retries (0 means unlimited)",
+			EnvVars:     []string{"NOUNIFY_RETRY_BUDGET"},
+			Destination: &x.budget,
+			Value:       defaults.Budget,
+		},
+	}
+}
+
+func (x *Retry) Config() retry.Config {
+	return retry.Config{
+		MaxAttempts: x.maxAttempts,
+		Interval:    x.interval,
+		MaxInterval: x.maxInterval,
+		Budget:      x.budget,
+	}
+}
+
+func (x *Retry) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Int("max_attempts", x.maxAttempts),
+		slog.Duration("interval", x.interval),
+		slog.Duration("max_interval", x.maxInterval),
+		slog.Duration("budget", x.budget),
+	)
+}
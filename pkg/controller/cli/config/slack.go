@@ -0,0 +1,100 @@
+package config
+
+import (
+	"log/slog"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/nounify/pkg/domain/types"
+	"github.com/m-mizutani/nounify/pkg/infra/retry"
+	"github.com/m-mizutani/nounify/pkg/infra/slackwebhook"
+	"github.com/m-mizutani/nounify/pkg/usecase"
+	"github.com/slack-go/slack"
+	"github.com/urfave/cli/v2"
+)
+
+type Slack struct {
+	oauthToken string
+	webhooks   cli.StringSlice
+	workspaces cli.StringSlice
+}
+
+func (x *Slack) Flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        "slack-oauth-token",
+			Usage:       "Slack OAuth token",
+			EnvVars:     []string{"NOUNIFY_SLACK_OAUTH_TOKEN"},
+			Destination: &x.oauthToken,
+		},
+		&cli.StringSliceFlag{
+			Name:        "slack-webhook",
+			Usage:       "Slack incoming webhook as <channel>=<url>. It is used instead of Slack OAuth token to post messages",
+			EnvVars:     []string{"NOUNIFY_SLACK_WEBHOOK"},
+			Destination: &x.webhooks,
+		},
+		&cli.StringSliceFlag{
+			Name:        "slack-workspace",
+			Usage:       "Slack OAuth token of additional workspace as <workspace>=<token>. The workspace is specified as workspace of messages",
+			EnvVars:     []string{"NOUNIFY_SLACK_WORKSPACE"},
+			Destination: &x.workspaces,
+		},
+	}
+}
+
+// Options returns usecase options to post messages to Slack by API with OAuth token, or by incoming webhooks. Additional workspaces are available only by API.
+func (x *Slack) Options(retryCfg retry.Config) ([]usecase.Option, error) {
+	hasWebhooks := len(x.webhooks.Value()) > 0
+	hasWorkspaces := len(x.workspaces.Value()) > 0
+
+	var options []usecase.Option
+	switch {
+	case x.oauthToken != "" && hasWebhooks:
+		return nil, goerr.New("slack-oauth-token and slack-webhook can not be used together")
+
+	case x.oauthToken != "":
+		client := retry.NewSlack(slack.New(x.oauthToken), retryCfg)
+		options = append(options, usecase.WithSlack(client))
+
+	case hasWebhooks:
+		webhooks, err := parseNamedValues("slack-webhook", x.webhooks.Value())
+		if err != nil {
+			return nil, err
+		}
+		notifier := retry.NewNotifier(slackwebhook.New(webhooks), types.DestinationSlack, retryCfg)
+		options = append(options, usecase.WithNotifier(types.DestinationSlack, notifier))
+
+	case !hasWorkspaces:
+		return nil, goerr.New("either slack-oauth-token, slack-webhook or slack-workspace is required")
+	}
+
+	if hasWorkspaces {
+		tokens, err := parseNamedValues("slack-workspace", x.workspaces.Value())
+		if err != nil {
+			return nil, err
+		}
+		for name, token := range tokens {
+			client := retry.NewSlack(slack.New(token), retryCfg)
+			options = append(options, usecase.WithSlackWorkspace(name, client))
+		}
+	}
+
+	return options, nil
+}
+
+func (x *Slack) LogValue() slog.Value {
+	// "workspace" mode has no default workspace, then messages require workspace
+	var mode string
+	switch {
+	case x.oauthToken != "":
+		mode = "api"
+	case len(x.webhooks.Value()) > 0:
+		mode = "webhook"
+	default:
+		mode = "workspace"
+	}
+	return slog.GroupValue(
+		slog.String("mode", mode),
+		slog.Any("webhooks", names(x.webhooks.Value())),
+		slog.Any("workspaces", names(x.workspaces.Value())),
+	)
+}
@@ -5,14 +5,18 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/m-mizutani/goerr"
 	"github.com/m-mizutani/nounify/pkg/controller/cli/config"
 	"github.com/m-mizutani/nounify/pkg/controller/server"
+	"github.com/m-mizutani/nounify/pkg/infra/file"
+	"github.com/m-mizutani/nounify/pkg/infra/memory"
 	"github.com/m-mizutani/nounify/pkg/usecase"
+	"github.com/m-mizutani/nounify/pkg/utils/errutil"
+	"github.com/m-mizutani/nounify/pkg/utils/logging"
 	"github.com/m-mizutani/opac"
-	"github.com/slack-go/slack"
 	"github.com/urfave/cli/v2"
 )
 
@@ -26,17 +30,32 @@ func joinFlags(flags ...[]cli.Flag) []cli.Flag {
 
 func cmdServe() *cli.Command {
 	var (
-		addr       string
-		slackToken string
-		ruleFiles  cli.StringSlice
+		addr           string
+		ruleFiles      cli.StringSlice
+		outboxDir      string
+		deadLetterDir  string
+		adminToken     string
+		dedupTTL       time.Duration
+		messageRefDir  string
+		messageRefTTL  time.Duration
+		maxMessageRefs int
 
 		githubSecrets           cli.StringSlice
 		enableGitHubActionToken bool
 		enableGoogleIDToken     bool
+		pubsubRequireIDToken    bool
+		githubActionJWKSURL     string
+		googleJWKSURL           string
+		jwksRefreshInterval     time.Duration
 		enableAwsSNS            bool
+		snsConfirmTopics        cli.StringSlice
 		enableAuthErrOK         bool
 
-		sentry config.Sentry
+		slackCfg config.Slack
+		sentry   config.Sentry
+		retryCfg config.Retry
+		async    config.Async
+		dests    config.Destinations
 	)
 
 	flags := joinFlags([]cli.Flag{
@@ -48,13 +67,6 @@ func cmdServe() *cli.Command {
 			Destination: &addr,
 			Value:       "127.0.0.1:8080",
 		},
-		&cli.StringFlag{
-			Name:        "slack-oauth-token",
-			Usage:       "Slack OAuth token",
-			EnvVars:     []string{"NOUNIFY_SLACK_OAUTH_TOKEN"},
-			Destination: &slackToken,
-			Required:    true,
-		},
 		&cli.StringSliceFlag{
 			Name:        "rule",
 			Usage:       "Path of rule file(s). When path is directory, all files in the directory are loaded. File extension must be .rego",
@@ -63,6 +75,50 @@ func cmdServe() *cli.Command {
 			Destination: &ruleFiles,
 			Required:    true,
 		},
+		&cli.StringFlag{
+			Name:        "outbox-dir",
+			Usage:       "Directory to persist messages until delivered. Pending messages are replayed at startup",
+			EnvVars:     []string{"NOUNIFY_OUTBOX_DIR"},
+			Destination: &outboxDir,
+		},
+		&cli.StringFlag{
+			Name:        "dead-letter-dir",
+			Usage:       "Directory to save messages that failed to be delivered",
+			EnvVars:     []string{"NOUNIFY_DEAD_LETTER_DIR"},
+			Destination: &deadLetterDir,
+		},
+		&cli.StringFlag{
+			Name:        "message-ref-dir",
+			Usage:       "Directory to persist references to posted messages for thread_key and update_key. References are kept in memory if not set",
+			EnvVars:     []string{"NOUNIFY_MESSAGE_REF_DIR"},
+			Destination: &messageRefDir,
+		},
+		&cli.DurationFlag{
+			Name:        "message-ref-ttl",
+			Usage:       "Period to keep an in-memory message reference since it is used last time (0 disables expiration)",
+			EnvVars:     []string{"NOUNIFY_MESSAGE_REF_TTL"},
+			Destination: &messageRefTTL,
+			Value:       memory.DefaultMessageRefTTL,
+		},
+		&cli.IntFlag{
+			Name:        "message-ref-max",
+			Usage:       "Maximum number of in-memory message references. The least recently used one is evicted (0 disables the limit)",
+			EnvVars:     []string{"NOUNIFY_MESSAGE_REF_MAX"},
+			Destination: &maxMessageRefs,
+			Value:       memory.DefaultMaxMessageRefs,
+		},
+		&cli.DurationFlag{
+			Name:        "dedup-ttl",
+			Usage:       "Period to ignore redelivered requests with the same delivery ID or dedup_key (0 disables deduplication)",
+			EnvVars:     []string{"NOUNIFY_DEDUP_TTL"},
+			Destination: &dedupTTL,
+		},
+		&cli.StringFlag{
+			Name:        "admin-token",
+			Usage:       "Bearer token for admin API (/admin). Admin API is disabled if not set",
+			EnvVars:     []string{"NOUNIFY_ADMIN_TOKEN"},
+			Destination: &adminToken,
+		},
 
 		&cli.StringSliceFlag{
 			Name:        "github-secret",
@@ -82,12 +138,43 @@ func cmdServe() *cli.Command {
 			EnvVars:     []string{"NOUNIFY_GOOGLE_ID_TOKEN"},
 			Destination: &enableGoogleIDToken,
 		},
+		&cli.StringFlag{
+			Name:        "github-action-jwks-url",
+			Usage:       "Override URL of JWK set for GitHub Actions token verification",
+			EnvVars:     []string{"NOUNIFY_GITHUB_ACTION_JWKS_URL"},
+			Destination: &githubActionJWKSURL,
+		},
+		&cli.StringFlag{
+			Name:        "google-jwks-url",
+			Usage:       "Override URL of JWK set for Google ID token verification",
+			EnvVars:     []string{"NOUNIFY_GOOGLE_JWKS_URL"},
+			Destination: &googleJWKSURL,
+		},
+		&cli.DurationFlag{
+			Name:        "jwks-refresh-interval",
+			Usage:       "Minimum interval to refresh JWK sets of GitHub Actions token and Google ID token in background (at least 1s)",
+			EnvVars:     []string{"NOUNIFY_JWKS_REFRESH_INTERVAL"},
+			Destination: &jwksRefreshInterval,
+			Value:       15 * time.Minute,
+		},
+		&cli.BoolFlag{
+			Name:        "pubsub-require-id-token",
+			Usage:       "Decode Google Pub/Sub push request into input.pubsub only when Google ID token is validated",
+			EnvVars:     []string{"NOUNIFY_PUBSUB_REQUIRE_ID_TOKEN"},
+			Destination: &pubsubRequireIDToken,
+		},
 		&cli.BoolFlag{
 			Name:        "aws-sns",
 			Usage:       "Enable Amazon SNS message verification",
 			EnvVars:     []string{"NOUNIFY_AWS_SNS"},
 			Destination: &enableAwsSNS,
 		},
+		&cli.StringSliceFlag{
+			Name:        "aws-sns-confirm-topic",
+			Usage:       "Confirm SNS subscription automatically if TopicArn matches the pattern (wildcard * is available). Requires --aws-sns",
+			EnvVars:     []string{"NOUNIFY_AWS_SNS_CONFIRM_TOPIC"},
+			Destination: &snsConfirmTopics,
+		},
 		&cli.BoolFlag{
 			Name:        "auth-err-ok",
 			Usage:       "Return 200 OK when authentication error",
@@ -95,7 +182,11 @@ func cmdServe() *cli.Command {
 			Destination: &enableAuthErrOK,
 		},
 	},
+		slackCfg.Flags(),
 		sentry.Flags(),
+		retryCfg.Flags(),
+		async.Flags(),
+		dests.Flags(),
 	)
 
 	return &cli.Command{
@@ -107,17 +198,71 @@ func cmdServe() *cli.Command {
 			if err := sentry.Configure(); err != nil {
 				return err
 			}
+			logging.Default().Info("Delivery retry", "retry", &retryCfg)
 
-			slackClient := slack.New(slackToken)
 			policy, err := opac.New(opac.Files(ruleFiles.Value()...))
 			if err != nil {
 				return goerr.Wrap(err, "failed to load policy files").With("files", ruleFiles.Value())
 			}
 
-			uc := usecase.New(
-				usecase.WithSlack(slackClient),
+			ucOptions := []usecase.Option{
 				usecase.WithPolicy(policy),
-			)
+				usecase.WithSuppression(memory.NewDedupStore()),
+			}
+			slackOptions, err := slackCfg.Options(retryCfg.Config())
+			if err != nil {
+				return err
+			}
+			ucOptions = append(ucOptions, slackOptions...)
+			logging.Default().Info("Slack", "slack", &slackCfg)
+			asyncOptions, err := async.Options()
+			if err != nil {
+				return err
+			}
+			ucOptions = append(ucOptions, asyncOptions...)
+			destOptions, err := dests.Options(retryCfg.Config())
+			if err != nil {
+				return err
+			}
+			ucOptions = append(ucOptions, destOptions...)
+			logging.Default().Info("Destinations", "destinations", &dests)
+			if outboxDir != "" {
+				outbox, err := file.NewOutbox(outboxDir)
+				if err != nil {
+					return err
+				}
+				ucOptions = append(ucOptions, usecase.WithOutbox(outbox))
+				logging.Default().Info("Enable outbox", "dir", outboxDir)
+			}
+			if messageRefDir != "" {
+				store, err := file.NewMessageRefStore(messageRefDir)
+				if err != nil {
+					return err
+				}
+				ucOptions = append(ucOptions, usecase.WithMessageRefStore(store))
+				logging.Default().Info("Enable file message ref store", "dir", messageRefDir)
+			} else {
+				store := memory.NewMessageRefStore(
+					memory.WithMessageRefTTL(messageRefTTL),
+					memory.WithMaxMessageRefs(maxMessageRefs),
+				)
+				ucOptions = append(ucOptions, usecase.WithMessageRefStore(store))
+			}
+			if dedupTTL > 0 {
+				ucOptions = append(ucOptions, usecase.WithDedup(memory.NewDedupStore(), dedupTTL))
+				logging.Default().Info("Enable deduplication", "ttl", dedupTTL)
+			}
+			if deadLetterDir != "" {
+				store, err := file.NewDeadLetterStore(deadLetterDir)
+				if err != nil {
+					return err
+				}
+				ucOptions = append(ucOptions, usecase.WithDeadLetterStore(store))
+				logging.Default().Info("Enable dead letter store", "dir", deadLetterDir)
+			}
+			logging.Default().Info("Delivery mode", "async", &async)
+
+			uc := usecase.New(ucOptions...)
 
 			serverOptions := []server.Option{
 				server.WithPolicy(policy),
@@ -128,21 +273,53 @@ func cmdServe() *cli.Command {
 			if enableGoogleIDToken {
 				serverOptions = append(serverOptions, server.WithGoogleIDTokenValidation())
 			}
+			if githubActionJWKSURL != "" {
+				serverOptions = append(serverOptions, server.WithGitHubActionJWKSURL(githubActionJWKSURL))
+			}
+			if googleJWKSURL != "" {
+				serverOptions = append(serverOptions, server.WithGoogleJWKSURL(googleJWKSURL))
+			}
+			if jwksRefreshInterval <= 0 {
+				return goerr.New("jwks-refresh-interval must be greater than 0").With("interval", jwksRefreshInterval)
+			}
+			serverOptions = append(serverOptions, server.WithJWKSRefreshInterval(jwksRefreshInterval))
+			if pubsubRequireIDToken {
+				serverOptions = append(serverOptions, server.WithPubSubIDTokenRequired())
+			}
 			if enableGitHubActionToken {
 				serverOptions = append(serverOptions, server.WithGitHubActionTokenValidation())
 			}
 			if enableAwsSNS {
 				serverOptions = append(serverOptions, server.WithAwsSNSValidation())
 			}
+			if topics := snsConfirmTopics.Value(); len(topics) > 0 {
+				if !enableAwsSNS {
+					return goerr.New("--aws-sns-confirm-topic requires --aws-sns")
+				}
+				serverOptions = append(serverOptions, server.WithAwsSNSAutoConfirm(topics...))
+				logging.Default().Info("Enable SNS subscription auto confirmation", "topics", topics)
+			}
 
 			if enableAuthErrOK {
 				serverOptions = append(serverOptions, server.WithAuthErrStatusCode(http.StatusOK))
 			}
+			if adminToken != "" {
+				serverOptions = append(serverOptions, server.WithAdminToken(adminToken))
+			}
+
+			// Stop background tasks of the server (e.g. refreshing JWK sets) on return
+			ctx, cancel := context.WithCancel(c.Context)
+			defer cancel()
 
 			s := &http.Server{
 				Addr:              addr,
 				ReadHeaderTimeout: 3 * time.Second,
-				Handler:           server.New(uc, serverOptions...),
+				Handler:           server.New(ctx, uc, serverOptions...),
+			}
+
+			// Replay must complete before listening. Otherwise, messages put into outbox by new requests are delivered twice.
+			if err := uc.ReplayOutbox(c.Context); err != nil {
+				errutil.Handle(c.Context, "failed to replay outbox", err)
 			}
 
 			errCh := make(chan error, 1)
@@ -154,7 +331,7 @@ func cmdServe() *cli.Command {
 			}()
 
 			sigCh := make(chan os.Signal, 1)
-			signal.Notify(sigCh, os.Interrupt)
+			signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
 
 			select {
 			case sig := <-sigCh:
@@ -165,6 +342,11 @@ func cmdServe() *cli.Command {
 					return goerr.Wrap(err, "failed to shutdown server").With("signal", sig)
 				}
 
+				// Drain queued messages after the server stops accepting new requests
+				if err := uc.Close(ctx); err != nil {
+					return goerr.Wrap(err, "failed to close usecase").With("signal", sig)
+				}
+
 			case err := <-errCh:
 				return err
 			}
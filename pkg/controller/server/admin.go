@@ -0,0 +1,119 @@
+package server
+
+import (
+	"crypto/subtle"
+	"expvar"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/nounify/pkg/domain/interfaces"
+	"github.com/m-mizutani/nounify/pkg/domain/types"
+)
+
+// authAdminToken requires Bearer token for admin API. It is independent from authentication of /msg.
+func authAdminToken(token string) middlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hdr := strings.SplitN(r.Header.Get("Authorization"), " ", 2)
+			if len(hdr) != 2 || strings.ToLower(hdr[0]) != "bearer" ||
+				subtle.ConstantTimeCompare([]byte(hdr[1]), []byte(token)) != 1 {
+				handleError(r.Context(), w, goerr.Wrap(types.ErrAuthFailed, "invalid admin token"))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func adminRoute(uc interfaces.UseCases, token string) func(r chi.Router) {
+	return func(r chi.Router) {
+		r.Use(logger)
+		r.Use(authAdminToken(token))
+
+		r.Route("/dead-letters", func(r chi.Router) {
+			r.Get("/", listDeadLetters(uc))
+			r.Delete("/", purgeDeadLetters(uc))
+			r.Get("/{id}", getDeadLetter(uc))
+			r.Delete("/{id}", deleteDeadLetter(uc))
+			r.Post("/{id}/redeliver", redeliverDeadLetter(uc))
+		})
+
+		// Metrics published by expvar, e.g. nounify_sns_cert_cache
+		r.Get("/vars", expvar.Handler().ServeHTTP)
+	}
+}
+
+func listDeadLetters(uc interfaces.UseCases) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		deadLetters, err := uc.ListDeadLetters(ctx)
+		if err != nil {
+			handleError(ctx, w, err)
+			return
+		}
+
+		writeJSON(ctx, w, http.StatusOK, map[string]any{
+			"dead_letters": deadLetters,
+		})
+	}
+}
+
+func getDeadLetter(uc interfaces.UseCases) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		dl, err := uc.GetDeadLetter(ctx, chi.URLParam(r, "id"))
+		if err != nil {
+			handleError(ctx, w, err)
+			return
+		}
+
+		writeJSON(ctx, w, http.StatusOK, dl)
+	}
+}
+
+func redeliverDeadLetter(uc interfaces.UseCases) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		report, err := uc.RedeliverDeadLetter(ctx, chi.URLParam(r, "id"))
+		if err != nil {
+			if report != nil {
+				handleError(ctx, w, err, handleErrorWithBody(report))
+			} else {
+				handleError(ctx, w, err)
+			}
+			return
+		}
+
+		writeJSON(ctx, w, http.StatusOK, report)
+	}
+}
+
+func deleteDeadLetter(uc interfaces.UseCases) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if err := uc.DeleteDeadLetter(ctx, chi.URLParam(r, "id")); err != nil {
+			handleError(ctx, w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func purgeDeadLetters(uc interfaces.UseCases) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		n, err := uc.PurgeDeadLetters(ctx)
+		if err != nil {
+			handleError(ctx, w, err)
+			return
+		}
+
+		writeJSON(ctx, w, http.StatusOK, map[string]any{
+			"purged": n,
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/nounify/pkg/controller/server"
+	"github.com/m-mizutani/nounify/pkg/domain/mock"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+	"github.com/m-mizutani/nounify/pkg/domain/types"
+)
+
+func TestAdminDeadLetters(t *testing.T) {
+	const adminToken = "admin-secret"
+
+	newMock := func() *mock.UseCasesMock {
+		return &mock.UseCasesMock{
+			ListDeadLettersFunc: func(ctx context.Context) ([]*model.DeadLetter, error) {
+				return []*model.DeadLetter{
+					{Delivery: model.Delivery{ID: "d1"}, Error: "channel_not_found"},
+				}, nil
+			},
+			GetDeadLetterFunc: func(ctx context.Context, id string) (*model.DeadLetter, error) {
+				return nil, types.ErrNotFound
+			},
+			RedeliverDeadLetterFunc: func(ctx context.Context, id string) (*model.DeliveryReport, error) {
+				return &model.DeliveryReport{
+					Results: []model.DeliveryResult{{Channel: "ch1", Status: types.DeliverySucceeded}},
+				}, nil
+			},
+			DeleteDeadLetterFunc: func(ctx context.Context, id string) error {
+				return nil
+			},
+			PurgeDeadLettersFunc: func(ctx context.Context) (int, error) {
+				return 3, nil
+			},
+		}
+	}
+
+	type testCase struct {
+		method  string
+		path    string
+		token   string
+		expCode int
+		check   func(t *testing.T, ucMock *mock.UseCasesMock, w *httptest.ResponseRecorder)
+	}
+
+	runTest := func(tc testCase) func(t *testing.T) {
+		return func(t *testing.T) {
+			ucMock := newMock()
+			mux := server.New(context.Background(), ucMock, server.WithAdminToken(adminToken))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tc.method, tc.path, nil)
+			if tc.token != "" {
+				r.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			mux.ServeHTTP(w, r)
+
+			gt.Equal(t, w.Code, tc.expCode)
+			if tc.check != nil {
+				tc.check(t, ucMock, w)
+			}
+		}
+	}
+
+	t.Run("list", runTest(testCase{
+		method:  http.MethodGet,
+		path:    "/admin/dead-letters",
+		token:   adminToken,
+		expCode: http.StatusOK,
+		check: func(t *testing.T, ucMock *mock.UseCasesMock, w *httptest.ResponseRecorder) {
+			var resp struct {
+				DeadLetters []*model.DeadLetter `json:"dead_letters"`
+			}
+			gt.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			gt.A(t, resp.DeadLetters).Length(1)
+			gt.Equal(t, resp.DeadLetters[0].ID, "d1")
+		},
+	}))
+
+	t.Run("without token", runTest(testCase{
+		method:  http.MethodGet,
+		path:    "/admin/dead-letters",
+		expCode: http.StatusUnauthorized,
+		check: func(t *testing.T, ucMock *mock.UseCasesMock, w *httptest.ResponseRecorder) {
+			gt.A(t, ucMock.ListDeadLettersCalls()).Length(0)
+		},
+	}))
+
+	t.Run("with wrong token", runTest(testCase{
+		method:  http.MethodGet,
+		path:    "/admin/dead-letters",
+		token:   "wrong",
+		expCode: http.StatusUnauthorized,
+	}))
+
+	t.Run("inspect not found", runTest(testCase{
+		method:  http.MethodGet,
+		path:    "/admin/dead-letters/unknown",
+		token:   adminToken,
+		expCode: http.StatusNotFound,
+	}))
+
+	t.Run("redeliver", runTest(testCase{
+		method:  http.MethodPost,
+		path:    "/admin/dead-letters/d1/redeliver",
+		token:   adminToken,
+		expCode: http.StatusOK,
+		check: func(t *testing.T, ucMock *mock.UseCasesMock, w *httptest.ResponseRecorder) {
+			gt.A(t, ucMock.RedeliverDeadLetterCalls()).Length(1)
+			gt.Equal(t, ucMock.RedeliverDeadLetterCalls()[0].ID, "d1")
+		},
+	}))
+
+	t.Run("delete", runTest(testCase{
+		method:  http.MethodDelete,
+		path:    "/admin/dead-letters/d1",
+		token:   adminToken,
+		expCode: http.StatusNoContent,
+	}))
+
+	t.Run("purge", runTest(testCase{
+		method:  http.MethodDelete,
+		path:    "/admin/dead-letters",
+		token:   adminToken,
+		expCode: http.StatusOK,
+		check: func(t *testing.T, ucMock *mock.UseCasesMock, w *httptest.ResponseRecorder) {
+			gt.A(t, ucMock.PurgeDeadLettersCalls()).Length(1)
+		},
+	}))
+}
+
+func TestAdminVars(t *testing.T) {
+	mux := server.New(context.Background(), &mock.UseCasesMock{}, server.WithAdminToken("admin-secret"))
+
+	t.Run("metrics are published", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/admin/vars", nil)
+		r.Header.Set("Authorization", "Bearer admin-secret")
+		mux.ServeHTTP(w, r)
+
+		gt.Equal(t, w.Code, http.StatusOK)
+		var vars map[string]any
+		gt.NoError(t, json.Unmarshal(w.Body.Bytes(), &vars))
+		gt.M(t, vars).HaveKey("nounify_sns_cert_cache")
+	})
+
+	t.Run("token is required", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/admin/vars", nil)
+		mux.ServeHTTP(w, r)
+		gt.Equal(t, w.Code, http.StatusUnauthorized)
+	})
+}
+
+func TestAdminDisabled(t *testing.T) {
+	mux := server.New(context.Background(), &mock.UseCasesMock{})
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/admin/dead-letters", nil)
+	r.Header.Set("Authorization", "Bearer ")
+	mux.ServeHTTP(w, r)
+	gt.Equal(t, w.Code, http.StatusNotFound)
+}
@@ -6,16 +6,13 @@ import (
 	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
-	"encoding/pem"
 	"errors"
 	"io"
 	"net/http"
-	"net/url"
 	"strings"
 	"time"
 
 	"github.com/google/go-github/v62/github"
-	"github.com/lestrrat-go/jwx/v2/jwk"
 	"github.com/lestrrat-go/jwx/v2/jwt"
 	"github.com/m-mizutani/goerr"
 	"github.com/m-mizutani/nounify/pkg/domain/interfaces"
@@ -55,7 +52,7 @@ func authGitHubWebhook(secret string) middlewareFunc {
 	}
 }
 
-func validateGitHubActionToken(authHdr string) (model.GitHubActionToken, error) {
+func validateGitHubActionToken(ctx context.Context, jwks *jwksCache, jwksURL, authHdr string) (model.GitHubActionToken, error) {
 	hdr := strings.SplitN(authHdr, " ", 2)
 
 	// Skip if not Bearer token
@@ -63,11 +60,9 @@ func validateGitHubActionToken(authHdr string) (model.GitHubActionToken, error)
 		return nil, nil
 	}
 
-	jwksURL := "https://token.actions.githubusercontent.com/.well-known/jwks"
-
-	set, err := jwk.Fetch(context.Background(), jwksURL)
+	set, err := jwks.get(ctx, jwksURL)
 	if err != nil {
-		return nil, goerr.Wrap(err)
+		return nil, err
 	}
 
 	token, err := jwt.ParseString(hdr[1], jwt.WithKeySet(set))
@@ -75,7 +70,7 @@ func validateGitHubActionToken(authHdr string) (model.GitHubActionToken, error)
 		return nil, goerr.Wrap(err, "failed to parse JWT token as GitHub Action token").With("token", trimToken(hdr[1]))
 	}
 
-	claims, err := token.AsMap(context.Background())
+	claims, err := token.AsMap(ctx)
 	if err != nil {
 		return nil, goerr.Wrap(err, "failed to convert JWT token to map").With("token", trimToken(hdr[1]))
 	}
@@ -83,10 +78,10 @@ func validateGitHubActionToken(authHdr string) (model.GitHubActionToken, error)
 	return claims, nil
 }
 
-func authGitHubActionToken() middlewareFunc {
+func authGitHubActionToken(jwks *jwksCache, jwksURL string) middlewareFunc {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			claims, err := validateGitHubActionToken(r.Header.Get("Authorization"))
+			claims, err := validateGitHubActionToken(r.Context(), jwks, jwksURL, r.Header.Get("Authorization"))
 			if claims == nil {
 				if err != nil {
 					ctxutil.Logger(r.Context()).Debug("failed to parse JWT token", "err", err)
@@ -101,7 +96,7 @@ func authGitHubActionToken() middlewareFunc {
 	}
 }
 
-func validateGoogleIDToken(authHdr string) (map[string]any, error) {
+func validateGoogleIDToken(ctx context.Context, jwks *jwksCache, jwksURL, authHdr string) (map[string]any, error) {
 	hdr := strings.SplitN(authHdr, " ", 2)
 
 	// Skip if not Bearer token
@@ -109,11 +104,9 @@ func validateGoogleIDToken(authHdr string) (map[string]any, error) {
 		return nil, nil
 	}
 
-	jwksURL := "https://www.googleapis.com/oauth2/v3/certs"
-
-	set, err := jwk.Fetch(context.Background(), jwksURL)
+	set, err := jwks.get(ctx, jwksURL)
 	if err != nil {
-		return nil, goerr.Wrap(err)
+		return nil, err
 	}
 
 	token, err := jwt.ParseString(hdr[1], jwt.WithKeySet(set))
@@ -121,7 +114,7 @@ func validateGoogleIDToken(authHdr string) (map[string]any, error) {
 		return nil, goerr.Wrap(err, "failed to parse JWT token as Google ID Token").With("token", trimToken(hdr[1]))
 	}
 
-	claims, err := token.AsMap(context.Background())
+	claims, err := token.AsMap(ctx)
 	if err != nil {
 		return nil, goerr.Wrap(err, "failed to convert JWT token to map").With("token", trimToken(hdr[1]))
 	}
@@ -129,10 +122,10 @@ func validateGoogleIDToken(authHdr string) (map[string]any, error) {
 	return claims, nil
 }
 
-func authGoogleIDToken() middlewareFunc {
+func authGoogleIDToken(jwks *jwksCache, jwksURL string) middlewareFunc {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			claims, err := validateGoogleIDToken(r.Header.Get("Authorization"))
+			claims, err := validateGoogleIDToken(r.Context(), jwks, jwksURL, r.Header.Get("Authorization"))
 			if claims == nil {
 				if err != nil {
 					ctxutil.Logger(r.Context()).Debug("failed to fetch JWK set", "err", err)
@@ -160,8 +153,11 @@ type snsMessage struct {
 	SigningCertURL   string `json:"SigningCertURL"`
 	SubscribeURL     string `json:"SubscribeURL"`
 	UnsubscribeURL   string `json:"UnsubscribeURL"`
+	// MessageAttributes is not covered by the signature
+	MessageAttributes map[string]model.SNSMessageAttribute `json:"MessageAttributes"`
 }
 
+// authAwsSNS verifies SNS messages
 func authAwsSNS() middlewareFunc {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -199,7 +195,7 @@ func validateSNSMessage(r *http.Request) (*model.AwsSNSAuth, error) {
 		return nil, goerr.Wrap(err, "invalid JSON format").With("body", string(body))
 	}
 
-	cert, err := fetchAWSCert(r.Context(), msg.SigningCertURL)
+	cert, err := defaultSNSCertCache.get(r.Context(), msg.SigningCertURL)
 	if err != nil {
 		return nil, goerr.Wrap(err, "failed to fetch certificate")
 	}
@@ -221,42 +217,6 @@ func validateSNSMessage(r *http.Request) (*model.AwsSNSAuth, error) {
 	}, nil
 }
 
-func fetchAWSCert(ctx context.Context, certURL string) (*x509.Certificate, error) {
-	if u, err := url.Parse(certURL); err != nil {
-		return nil, goerr.Wrap(err, "invalid URL").With("url", certURL)
-	} else if u.Scheme != "https" || !strings.HasSuffix(u.Host, ".amazonaws.com") {
-		return nil, goerr.New("unacceptable URL").With("url", certURL)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, certURL, nil)
-	if err != nil {
-		return nil, goerr.Wrap(err, "failed to create request").With("url", certURL)
-	}
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, goerr.Wrap(err, "failed to fetch certificate").With("url", certURL)
-	}
-	defer resp.Body.Close()
-
-	certData, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, goerr.Wrap(err, "failed to read certificate data")
-	}
-
-	block, _ := pem.Decode(certData)
-	if block == nil {
-		return nil, goerr.New("failed to decode PEM block")
-	}
-
-	cert, err := x509.ParseCertificate(block.Bytes)
-	if err != nil {
-		return nil, goerr.Wrap(err, "failed to parse certificate")
-	}
-
-	return cert, nil
-}
-
 func buildSNSMessageString(message snsMessage) (string, error) {
 	var msgParts []string
 
@@ -36,8 +36,8 @@ var policyGoogleAuth string
 func TestGitHubAppAuth(t *testing.T) {
 	const testSecret = "test-test-test"
 	ucMock := &mock.UseCasesMock{
-		HandleMessageFunc: func(ctx context.Context, schema types.Schema, input *model.MessageQueryInput) error {
-			return nil
+		HandleMessageFunc: func(ctx context.Context, schema types.Schema, input *model.MessageQueryInput) (*model.DeliveryReport, error) {
+			return &model.DeliveryReport{}, nil
 		},
 	}
 	w := httptest.NewRecorder()
@@ -53,7 +53,7 @@ func TestGitHubAppAuth(t *testing.T) {
 	req.Header.Set("X-GitHub-Event", "push")
 	req.Header.Set("X-Hub-Signature-256", "sha256="+signature)
 
-	mux := server.New(ucMock,
+	mux := server.New(context.Background(), ucMock,
 		server.WithGitHubSecret(testSecret),
 		server.WithPolicy(policy),
 	)
@@ -68,12 +68,12 @@ func TestGitHubActionToken(t *testing.T) {
 	gt.NoError(t, err)
 
 	ucMock := &mock.UseCasesMock{
-		HandleMessageFunc: func(ctx context.Context, schema types.Schema, input *model.MessageQueryInput) error {
-			return nil
+		HandleMessageFunc: func(ctx context.Context, schema types.Schema, input *model.MessageQueryInput) (*model.DeliveryReport, error) {
+			return &model.DeliveryReport{}, nil
 		},
 	}
 
-	mux := server.New(ucMock,
+	mux := server.New(context.Background(), ucMock,
 		server.WithGitHubActionTokenValidation(),
 		server.WithPolicy(policy),
 	)
@@ -120,11 +120,11 @@ func TestGoogleIDTokenAuth(t *testing.T) {
 	runTest := func(tc testCase) func(t *testing.T) {
 		return func(t *testing.T) {
 			ucMock := &mock.UseCasesMock{
-				HandleMessageFunc: func(ctx context.Context, schema types.Schema, input *model.MessageQueryInput) error {
+				HandleMessageFunc: func(ctx context.Context, schema types.Schema, input *model.MessageQueryInput) (*model.DeliveryReport, error) {
 					v, ok := input.Auth.Google["exp"].(int64)
 					gt.True(t, ok)
 					gt.N(t, v).Greater(0)
-					return nil
+					return &model.DeliveryReport{}, nil
 				},
 			}
 
@@ -132,7 +132,7 @@ func TestGoogleIDTokenAuth(t *testing.T) {
 			policy, err := opac.New(opac.Data(map[string]string{"auth": policyGoogleAuth}))
 			gt.NoError(t, err)
 
-			mux := server.New(ucMock,
+			mux := server.New(context.Background(), ucMock,
 				server.WithGoogleIDTokenValidation(),
 				server.WithPolicy(policy),
 				server.WithAuthErrStatusCode(tc.forceCode),
@@ -1,4 +1,51 @@
 package server
 
+import (
+	"context"
+	"crypto/x509"
+	"expvar"
+	"net/http"
+	"time"
+
+	"github.com/m-mizutani/nounify/pkg/domain/interfaces"
+)
+
 var ValidateSNSMessage = validateSNSMessage
 var Logger = logger
+
+func NewSNSConfirmer(topics []string, client *http.Client) *snsConfirmer {
+	return &snsConfirmer{topics: topics, client: client}
+}
+
+func (x *snsConfirmer) Handle(r *http.Request) (bool, error) {
+	return x.handle(r)
+}
+
+var ValidateSNSURL = validateSNSURL
+
+func NewSNSCertCache(client *http.Client, roots *x509.CertPool, now func() time.Time) *snsCertCache {
+	x := newSNSCertCache(client)
+	x.roots = roots
+	x.now = now
+	return x
+}
+
+func (x *snsCertCache) Get(ctx context.Context, certURL string) (*x509.Certificate, error) {
+	return x.get(ctx, certURL)
+}
+
+func SNSCertMetric(name string) int64 {
+	v, ok := snsCertMetrics.Get(name).(*expvar.Int)
+	if !ok {
+		return 0
+	}
+	return v.Value()
+}
+
+func AuthWithPolicy(policy interfaces.Policy, errCode int) func(http.Handler) http.Handler {
+	return authWithPolicy(policy, errCode)
+}
+
+func ConfirmAwsSNS(confirmer *snsConfirmer) func(http.Handler) http.Handler {
+	return confirmAwsSNS(confirmer)
+}
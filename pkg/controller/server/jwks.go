@@ -0,0 +1,72 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/nounify/pkg/infra/httpclient"
+	"github.com/m-mizutani/nounify/pkg/utils/logging"
+)
+
+const (
+	defaultGitHubActionJWKSURL = "https://token.actions.githubusercontent.com/.well-known/jwks"
+	defaultGoogleJWKSURL       = "https://www.googleapis.com/oauth2/v3/certs"
+
+	// defaultJWKSRefreshInterval is the minimum interval to refresh JWK set even if Cache-Control of the response is shorter
+	defaultJWKSRefreshInterval = 15 * time.Minute
+	// jwksMinRefreshWindow is the shortest interval to check refreshes supported by the cache
+	jwksMinRefreshWindow = time.Second
+)
+
+// jwksErrSink logs errors of background refresh. The previous JWK set is kept and used on error.
+type jwksErrSink struct{}
+
+func (jwksErrSink) Error(err error) {
+	logging.Default().Warn("failed to refresh JWK set, keep using the cached one", "error", err)
+}
+
+// jwksCache keeps JWK sets and refreshes them in background. Once a JWK set is fetched, it is used until refresh succeeds even if the endpoint fails.
+type jwksCache struct {
+	cache    *jwk.Cache
+	interval time.Duration
+}
+
+// newJWKSCache starts refreshing JWK sets in background until ctx is done. The interval is rounded up to jwksMinRefreshWindow because the cache checks refreshes only every second.
+func newJWKSCache(ctx context.Context, interval time.Duration) *jwksCache {
+	if interval < jwksMinRefreshWindow {
+		interval = jwksMinRefreshWindow
+	}
+
+	return &jwksCache{
+		cache: jwk.NewCache(ctx,
+			jwk.WithErrSink(jwksErrSink{}),
+			jwk.WithRefreshWindow(interval),
+		),
+		interval: interval,
+	}
+}
+
+func (x *jwksCache) register(jwksURL string) error {
+	if x.cache.IsRegistered(jwksURL) {
+		return nil
+	}
+
+	if err := x.cache.Register(jwksURL,
+		jwk.WithMinRefreshInterval(x.interval),
+		jwk.WithHTTPClient(httpclient.New()),
+	); err != nil {
+		return goerr.Wrap(err, "failed to register JWKS URL").With("url", jwksURL)
+	}
+	return nil
+}
+
+// get returns the cached JWK set. It fetches the set synchronously only at the first time.
+func (x *jwksCache) get(ctx context.Context, jwksURL string) (jwk.Set, error) {
+	set, err := x.cache.Get(ctx, jwksURL)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get JWK set").With("url", jwksURL)
+	}
+	return set, nil
+}
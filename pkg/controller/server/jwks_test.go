@@ -0,0 +1,147 @@
+package server_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/nounify/pkg/controller/server"
+	"github.com/m-mizutani/nounify/pkg/domain/mock"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+	"github.com/m-mizutani/nounify/pkg/domain/types"
+	"github.com/m-mizutani/opac"
+)
+
+// newJWKSServer returns a local stand-in of JWKS endpoint and a function to sign a token with the key
+func newJWKSServer(t *testing.T, fail *atomic.Bool, calls *atomic.Int32) (*httptest.Server, func(claims map[string]any) string) {
+	t.Helper()
+	raw := gt.R1(rsa.GenerateKey(rand.Reader, 2048)).NoError(t)
+	key := gt.R1(jwk.FromRaw(raw)).NoError(t)
+	gt.NoError(t, key.Set(jwk.KeyIDKey, "test-key"))
+	gt.NoError(t, key.Set(jwk.AlgorithmKey, jwa.RS256))
+
+	pub := gt.R1(key.PublicKey()).NoError(t)
+	set := jwk.NewSet()
+	gt.NoError(t, set.AddKey(pub))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if fail.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		gt.NoError(t, json.NewEncoder(w).Encode(set))
+	}))
+	t.Cleanup(srv.Close)
+
+	sign := func(claims map[string]any) string {
+		builder := jwt.NewBuilder().Expiration(time.Now().Add(time.Hour))
+		for k, v := range claims {
+			builder = builder.Claim(k, v)
+		}
+		token := gt.R1(builder.Build()).NoError(t)
+		signed := gt.R1(jwt.Sign(token, jwt.WithKey(jwa.RS256, key))).NoError(t)
+		return string(signed)
+	}
+
+	return srv, sign
+}
+
+func TestJWKSCache(t *testing.T) {
+	type testCase struct {
+		options func(url string) []server.Option
+		policy  string
+		claims  map[string]any
+		check   func(t *testing.T, input *model.MessageQueryInput)
+	}
+
+	runTest := func(tc testCase) func(t *testing.T) {
+		return func(t *testing.T) {
+			t.Parallel()
+			var fail atomic.Bool
+			var calls atomic.Int32
+			srv, sign := newJWKSServer(t, &fail, &calls)
+
+			policy := gt.R1(opac.New(opac.Data(map[string]string{"auth": tc.policy}))).NoError(t)
+			ucMock := &mock.UseCasesMock{
+				HandleMessageFunc: func(ctx context.Context, schema types.Schema, input *model.MessageQueryInput) (*model.DeliveryReport, error) {
+					tc.check(t, input)
+					return &model.DeliveryReport{}, nil
+				},
+			}
+			// Background refresh stops at the end of the test
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+
+			options := append(tc.options(srv.URL),
+				server.WithPolicy(policy),
+				server.WithJWKSRefreshInterval(time.Second),
+			)
+			mux := server.New(ctx, ucMock, options...)
+
+			send := func() int {
+				w := httptest.NewRecorder()
+				req := httptest.NewRequest(http.MethodPost, "/msg/test", strings.NewReader("{}"))
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set("Authorization", "Bearer "+sign(tc.claims))
+				mux.ServeHTTP(w, req)
+				return w.Code
+			}
+
+			gt.Equal(t, send(), http.StatusOK)
+			gt.Equal(t, send(), http.StatusOK)
+			// JWK set is fetched only once for multiple requests
+			gt.Equal(t, calls.Load(), int32(1))
+
+			// Wait for background refresh failing, then the cached JWK set is still used
+			fail.Store(true)
+			deadline := time.Now().Add(5 * time.Second)
+			for calls.Load() < 2 && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+			gt.N(t, calls.Load()).GreaterOrEqual(2)
+			gt.Equal(t, send(), http.StatusOK)
+			gt.A(t, ucMock.HandleMessageCalls()).Length(3)
+		}
+	}
+
+	t.Run("GitHub Action token", runTest(testCase{
+		options: func(url string) []server.Option {
+			return []server.Option{
+				server.WithGitHubActionTokenValidation(),
+				server.WithGitHubActionJWKSURL(url),
+			}
+		},
+		policy: policyGitHubAction,
+		claims: map[string]any{"actor": "m-mizutani"},
+		check: func(t *testing.T, input *model.MessageQueryInput) {
+			gt.Equal(t, input.Auth.GitHub.Action["actor"], any("m-mizutani"))
+		},
+	}))
+
+	t.Run("Google ID token", runTest(testCase{
+		options: func(url string) []server.Option {
+			return []server.Option{
+				server.WithGoogleIDTokenValidation(),
+				server.WithGoogleJWKSURL(url),
+			}
+		},
+		policy: policyGoogleAuth,
+		claims: map[string]any{"email": "mizutani@example.com", "email_verified": true, "iss": "https://accounts.google.com"},
+		check: func(t *testing.T, input *model.MessageQueryInput) {
+			gt.Equal(t, input.Auth.Google["email"], any("mizutani@example.com"))
+		},
+	}))
+}
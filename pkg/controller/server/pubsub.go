@@ -0,0 +1,54 @@
+package server
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+	"github.com/m-mizutani/nounify/pkg/domain/types"
+)
+
+// decodePubSub decodes a push request of Google Pub/Sub. It returns nil if the body is not a Pub/Sub envelope.
+func decodePubSub(body []byte) (*model.InputPubSub, error) {
+	var header struct {
+		Message struct {
+			MessageID string `json:"messageId"`
+		} `json:"message"`
+		Subscription string `json:"subscription"`
+	}
+	if err := json.Unmarshal(body, &header); err != nil || header.Message.MessageID == "" || header.Subscription == "" {
+		return nil, nil
+	}
+
+	var envelope model.PubSubEnvelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, goerr.Wrap(types.ErrInvalidInput.Wrap(err), "invalid Pub/Sub message").With("message_id", header.Message.MessageID)
+	}
+	msg := envelope.Message
+
+	input := &model.InputPubSub{
+		Attributes:   msg.Attributes,
+		MessageID:    msg.MessageID,
+		Subscription: envelope.Subscription,
+	}
+
+	var data any
+	if err := json.Unmarshal(msg.Data, &data); err == nil {
+		input.Data = data
+	} else {
+		input.Data = string(msg.Data)
+	}
+
+	if msg.PublishTime != "" {
+		t, err := time.Parse(time.RFC3339Nano, msg.PublishTime)
+		if err != nil {
+			return nil, goerr.Wrap(types.ErrInvalidInput.Wrap(err), "invalid publishTime of Pub/Sub message").
+				With("message_id", msg.MessageID).
+				With("publish_time", msg.PublishTime)
+		}
+		input.PublishTime = t
+	}
+
+	return input, nil
+}
@@ -8,12 +8,14 @@ import (
 	"mime"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/m-mizutani/goerr"
 	"github.com/m-mizutani/nounify/pkg/domain/interfaces"
 	"github.com/m-mizutani/nounify/pkg/domain/model"
 	"github.com/m-mizutani/nounify/pkg/domain/types"
+	"github.com/m-mizutani/nounify/pkg/utils/ctxutil"
 	"github.com/m-mizutani/nounify/pkg/utils/errutil"
 )
 
@@ -24,6 +26,12 @@ type config struct {
 	validateGoogleIDToken     bool
 	validateAwsSNS            bool
 	authErrStatusCode         int
+	adminToken                string
+	pubsubRequireIDToken      bool
+	snsConfirmTopics          []string
+	githubActionJWKSURL       string
+	googleJWKSURL             string
+	jwksRefreshInterval       time.Duration
 }
 
 type Option func(*config)
@@ -46,6 +54,27 @@ func WithGitHubActionTokenValidation() Option {
 	}
 }
 
+// WithGitHubActionJWKSURL overrides the URL of JWK set to validate GitHub Actions OIDC token, e.g. for GitHub Enterprise Server or tests.
+func WithGitHubActionJWKSURL(jwksURL string) Option {
+	return func(cfg *config) {
+		cfg.githubActionJWKSURL = jwksURL
+	}
+}
+
+// WithJWKSRefreshInterval sets the minimum interval to refresh JWK sets in background.
+func WithJWKSRefreshInterval(interval time.Duration) Option {
+	return func(cfg *config) {
+		cfg.jwksRefreshInterval = interval
+	}
+}
+
+// WithGoogleJWKSURL overrides the URL of JWK set to validate Google ID token, e.g. for a local stand-in.
+func WithGoogleJWKSURL(jwksURL string) Option {
+	return func(cfg *config) {
+		cfg.googleJWKSURL = jwksURL
+	}
+}
+
 func WithGoogleIDTokenValidation() Option {
 	return func(cfg *config) {
 		cfg.validateGoogleIDToken = true
@@ -58,20 +87,51 @@ func WithAwsSNSValidation() Option {
 	}
 }
 
+// WithPubSubIDTokenRequired decodes Google Pub/Sub push requests into input.pubsub only when Google ID token of the request has been validated.
+func WithPubSubIDTokenRequired() Option {
+	return func(cfg *config) {
+		cfg.pubsubRequireIDToken = true
+	}
+}
+
+// WithAwsSNSAutoConfirm confirms SNS subscriptions automatically when TopicArn matches one of the patterns. It works with WithAwsSNSValidation because only verified messages are confirmed. If the auth policy is set, the request must also be allowed by the policy.
+func WithAwsSNSAutoConfirm(topics ...string) Option {
+	return func(cfg *config) {
+		cfg.snsConfirmTopics = append(cfg.snsConfirmTopics, topics...)
+	}
+}
+
 func WithAuthErrStatusCode(code int) Option {
 	return func(cfg *config) {
 		cfg.authErrStatusCode = code
 	}
 }
 
-func New(uc interfaces.UseCases, options ...Option) http.Handler {
+// WithAdminToken enables admin API under /admin with the Bearer token. The admin API is disabled without the token.
+func WithAdminToken(token string) Option {
+	return func(cfg *config) {
+		cfg.adminToken = token
+	}
+}
+
+// New returns HTTP handler of nounify. Background tasks of the handler (e.g. refreshing JWK sets) stop when ctx is done.
+func New(ctx context.Context, uc interfaces.UseCases, options ...Option) http.Handler {
 	cfg := &config{
-		authErrStatusCode: http.StatusForbidden,
+		authErrStatusCode:   http.StatusForbidden,
+		githubActionJWKSURL: defaultGitHubActionJWKSURL,
+		googleJWKSURL:       defaultGoogleJWKSURL,
+		jwksRefreshInterval: defaultJWKSRefreshInterval,
 	}
 	for _, opt := range options {
 		opt(cfg)
 	}
 
+	// JWK sets are shared by all requests and refreshed in background
+	var jwks *jwksCache
+	if cfg.validateGitHubActionToken || cfg.validateGoogleIDToken {
+		jwks = newJWKSCache(ctx, cfg.jwksRefreshInterval)
+	}
+
 	route := chi.NewRouter()
 	route.Get("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -83,10 +143,16 @@ func New(uc interfaces.UseCases, options ...Option) http.Handler {
 			r.Use(authGitHubWebhook(secret))
 		}
 		if cfg.validateGitHubActionToken {
-			r.Use(authGitHubActionToken())
+			if err := jwks.register(cfg.githubActionJWKSURL); err != nil {
+				errutil.Handle(context.Background(), "failed to enable GitHub Action token validation", err)
+			}
+			r.Use(authGitHubActionToken(jwks, cfg.githubActionJWKSURL))
 		}
 		if cfg.validateGoogleIDToken {
-			r.Use(authGoogleIDToken())
+			if err := jwks.register(cfg.googleJWKSURL); err != nil {
+				errutil.Handle(context.Background(), "failed to enable Google ID token validation", err)
+			}
+			r.Use(authGoogleIDToken(jwks, cfg.googleJWKSURL))
 		}
 		if cfg.validateAwsSNS {
 			r.Use(authAwsSNS())
@@ -96,14 +162,24 @@ func New(uc interfaces.UseCases, options ...Option) http.Handler {
 			r.Use(authWithPolicy(cfg.policy, cfg.authErrStatusCode))
 		}
 
-		r.Post("/*", handleMessage(uc))
+		// Subscription is confirmed after the request passes the auth policy
+		if cfg.validateAwsSNS && len(cfg.snsConfirmTopics) > 0 {
+			r.Use(confirmAwsSNS(newSNSConfirmer(cfg.snsConfirmTopics)))
+		}
+
+		r.Post("/*", handleMessage(uc, cfg))
 	})
 
+	if cfg.adminToken != "" {
+		route.Route("/admin", adminRoute(uc, cfg.adminToken))
+	}
+
 	return route
 }
 
 type handleErrorOpt struct {
 	forceCode int
+	body      any
 }
 
 type handleErrorOption func(o *handleErrorOpt)
@@ -114,6 +190,13 @@ func handleErrorWithForceCode(code int) handleErrorOption {
 	}
 }
 
+// handleErrorWithBody responds body as JSON instead of the error message
+func handleErrorWithBody(body any) handleErrorOption {
+	return func(o *handleErrorOpt) {
+		o.body = body
+	}
+}
+
 func handleError(ctx context.Context, w http.ResponseWriter, err error, options ...handleErrorOption) {
 	opt := &handleErrorOpt{}
 	for _, o := range options {
@@ -131,10 +214,22 @@ func handleError(ctx context.Context, w http.ResponseWriter, err error, options
 	if opt.forceCode > 0 {
 		code = opt.forceCode
 	}
+	if opt.body != nil {
+		writeJSON(ctx, w, code, opt.body)
+		return
+	}
 	http.Error(w, err.Error(), code)
 }
 
-func newMessageQueryInput(r *http.Request) (*model.MessageQueryInput, error) {
+func writeJSON(ctx context.Context, w http.ResponseWriter, code int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		errutil.Handle(ctx, "failed to write response", err)
+	}
+}
+
+func newMessageQueryInput(r *http.Request, cfg *config) (*model.MessageQueryInput, error) {
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
 		return nil, goerr.Wrap(types.ErrInvalidInput.Wrap(err)).With("method", r.Method).With("path", r.URL.Path)
@@ -146,6 +241,8 @@ func newMessageQueryInput(r *http.Request) (*model.MessageQueryInput, error) {
 	}
 
 	var data any
+	var pubsub *model.InputPubSub
+	var sns *model.InputSNS
 	switch mediaType {
 	case "application/json":
 		if err := json.Unmarshal(body, &data); err != nil {
@@ -155,6 +252,12 @@ func newMessageQueryInput(r *http.Request) (*model.MessageQueryInput, error) {
 				With("body", string(body))
 		}
 
+		if !cfg.pubsubRequireIDToken || ctxutil.GoogleIDToken(r.Context()) != nil {
+			if pubsub, err = decodePubSub(body); err != nil {
+				return nil, goerr.Wrap(err).With("method", r.Method).With("path", r.URL.Path)
+			}
+		}
+
 	case "text/plain":
 		if r.Header.Get("X-Amz-Sns-Message-Id") != "" {
 			if err := json.Unmarshal(body, &data); err != nil {
@@ -163,6 +266,9 @@ func newMessageQueryInput(r *http.Request) (*model.MessageQueryInput, error) {
 					With("path", r.URL.Path).
 					With("body", string(body))
 			}
+			if sns, err = decodeSNS(body); err != nil {
+				return nil, goerr.Wrap(err).With("method", r.Method).With("path", r.URL.Path)
+			}
 		} else {
 			data = string(body)
 		}
@@ -182,25 +288,42 @@ func newMessageQueryInput(r *http.Request) (*model.MessageQueryInput, error) {
 		Header: headers,
 		Body:   data,
 		Auth:   authFromContext(r.Context()),
+		PubSub: pubsub,
+		SNS:    sns,
 	}, nil
 }
 
-func handleMessage(uc interfaces.UseCases) http.HandlerFunc {
+func handleMessage(uc interfaces.UseCases, cfg *config) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 		schema := strings.Replace(chi.URLParam(r, "*"), "/", ".", -1)
 
-		input, err := newMessageQueryInput(r)
+		input, err := newMessageQueryInput(r, cfg)
 		if err != nil {
 			handleError(ctx, w, err)
 			return
 		}
 
-		if err := uc.HandleMessage(ctx, types.Schema(schema), input); err != nil {
-			handleError(ctx, w, err)
+		report, err := uc.HandleMessage(ctx, types.Schema(schema), input)
+		if err != nil {
+			if report != nil {
+				options := []handleErrorOption{handleErrorWithBody(report)}
+				// Partial failure should not be retried by the sender, because the retry posts the delivered messages again
+				if report.Count(types.DeliverySucceeded)+report.Count(types.DeliveryQueued)+report.Count(types.DeliveryBuffered) > 0 {
+					options = append(options, handleErrorWithForceCode(http.StatusMultiStatus))
+				}
+				handleError(ctx, w, err, options...)
+			} else {
+				handleError(ctx, w, err)
+			}
 			return
 		}
 
-		w.WriteHeader(http.StatusOK)
+		// Queued and buffered messages have not been delivered yet
+		code := http.StatusOK
+		if report.Count(types.DeliveryQueued)+report.Count(types.DeliveryBuffered) > 0 {
+			code = http.StatusAccepted
+		}
+		writeJSON(ctx, w, code, report)
 	}
 }
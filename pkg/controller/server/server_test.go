@@ -3,9 +3,12 @@ package server_test
 import (
 	"bytes"
 	"context"
+	_ "embed"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/m-mizutani/gt"
 
@@ -20,7 +23,7 @@ func TestHealthCheck(t *testing.T) {
 	w := httptest.NewRecorder()
 
 	r := httptest.NewRequest(http.MethodGet, "/health", nil)
-	mux := server.New(&ucMock)
+	mux := server.New(context.Background(), &ucMock)
 	mux.ServeHTTP(w, r)
 	gt.Equal(t, w.Code, http.StatusOK)
 }
@@ -36,13 +39,13 @@ func TestServer(t *testing.T) {
 	test := func(tc testCase) func(*testing.T) {
 		return func(t *testing.T) {
 			ucMock := mock.UseCasesMock{
-				HandleMessageFunc: func(ctx context.Context, schema types.Schema, input *model.MessageQueryInput) error {
-					return nil
+				HandleMessageFunc: func(ctx context.Context, schema types.Schema, input *model.MessageQueryInput) (*model.DeliveryReport, error) {
+					return &model.DeliveryReport{}, nil
 				},
 			}
 			w := httptest.NewRecorder()
 
-			mux := server.New(&ucMock)
+			mux := server.New(context.Background(), &ucMock)
 			mux.ServeHTTP(w, tc.req())
 
 			gt.Equal(t, w.Code, tc.expCode)
@@ -97,7 +100,7 @@ func TestParseRequest(t *testing.T) {
 		req     func() *http.Request
 		expCode int
 		expCall int
-		mock    func(ctx context.Context, schema types.Schema, input *model.MessageQueryInput) error
+		mock    func(ctx context.Context, schema types.Schema, input *model.MessageQueryInput) (*model.DeliveryReport, error)
 	}
 
 	test := func(tc testCase) func(*testing.T) {
@@ -107,7 +110,7 @@ func TestParseRequest(t *testing.T) {
 			}
 			w := httptest.NewRecorder()
 
-			mux := server.New(&ucMock)
+			mux := server.New(context.Background(), &ucMock)
 			mux.ServeHTTP(w, tc.req())
 
 			gt.Equal(t, w.Code, tc.expCode)
@@ -124,11 +127,200 @@ func TestParseRequest(t *testing.T) {
 		},
 		expCode: http.StatusOK,
 		expCall: 1,
-		mock: func(ctx context.Context, schema types.Schema, input *model.MessageQueryInput) error {
+		mock: func(ctx context.Context, schema types.Schema, input *model.MessageQueryInput) (*model.DeliveryReport, error) {
 			data, ok := input.Body.(map[string]any)
 			gt.True(t, ok)
 			gt.Equal(t, data["Type"], "Notification")
-			return nil
+
+			gt.NotEqual(t, input.SNS, nil)
+			gt.Equal(t, input.SNS.Subject, "test")
+			gt.Equal(t, input.SNS.Message, any("this is test"))
+			return &model.DeliveryReport{}, nil
+		},
+	}))
+
+	t.Run("amazon SNS with JSON message", test(testCase{
+		req: func() *http.Request {
+			body := `{"Type":"Notification","MessageId":"m1","TopicArn":"arn:aws:sns:us-east-1:123456789012:alarms",` +
+				`"Message":"{\"AlarmName\":\"high-cpu\",\"NewStateValue\":\"ALARM\"}",` +
+				`"MessageAttributes":{"env":{"Type":"String","Value":"prod"}}}`
+			r := httptest.NewRequest(http.MethodPost, "/msg/schema", bytes.NewReader([]byte(body)))
+			r.Header.Set("X-Amz-Sns-Message-Id", "m1")
+			r.Header.Set("Content-Type", "text/plain; charset=UTF-8")
+			return r
+		},
+		expCode: http.StatusOK,
+		expCall: 1,
+		mock: func(ctx context.Context, schema types.Schema, input *model.MessageQueryInput) (*model.DeliveryReport, error) {
+			gt.NotEqual(t, input.SNS, nil)
+			gt.Equal(t, input.SNS.TopicArn, "arn:aws:sns:us-east-1:123456789012:alarms")
+			gt.Equal(t, input.SNS.MessageAttributes["env"], model.SNSMessageAttribute{Type: "String", Value: "prod"})
+
+			msg, ok := input.SNS.Message.(map[string]any)
+			gt.True(t, ok)
+			gt.Equal(t, msg["AlarmName"], any("high-cpu"))
+			return &model.DeliveryReport{}, nil
+		},
+	}))
+
+	t.Run("not SNS", test(testCase{
+		req: func() *http.Request {
+			r := httptest.NewRequest(http.MethodPost, "/msg/schema", bytes.NewReader([]byte("hello")))
+			r.Header.Set("Content-Type", "text/plain")
+			return r
+		},
+		expCode: http.StatusOK,
+		expCall: 1,
+		mock: func(ctx context.Context, schema types.Schema, input *model.MessageQueryInput) (*model.DeliveryReport, error) {
+			gt.Equal(t, input.SNS, nil)
+			gt.Equal(t, input.Body, any("hello"))
+			return &model.DeliveryReport{}, nil
 		},
 	}))
 }
+
+//go:embed testdata/pubsub_push.json
+var pubsubPush []byte
+
+func TestPubSub(t *testing.T) {
+	newReq := func(body []byte) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/msg/schema", bytes.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		return r
+	}
+
+	handle := func(t *testing.T, r *http.Request, options ...server.Option) (int, *model.MessageQueryInput) {
+		var input *model.MessageQueryInput
+		ucMock := &mock.UseCasesMock{
+			HandleMessageFunc: func(ctx context.Context, schema types.Schema, in *model.MessageQueryInput) (*model.DeliveryReport, error) {
+				input = in
+				return &model.DeliveryReport{}, nil
+			},
+		}
+		w := httptest.NewRecorder()
+		server.New(context.Background(), ucMock, options...).ServeHTTP(w, r)
+		return w.Code, input
+	}
+
+	t.Run("decode push envelope", func(t *testing.T) {
+		code, input := handle(t, newReq(pubsubPush))
+		gt.Equal(t, code, http.StatusOK)
+		gt.NotEqual(t, input.PubSub, nil)
+		gt.Equal(t, input.PubSub.MessageID, "2070443601311540")
+		gt.Equal(t, input.PubSub.Subscription, "projects/my-project/subscriptions/nounify")
+		gt.Equal(t, input.PubSub.Attributes["eventType"], "OBJECT_FINALIZE")
+		gt.Equal(t, input.PubSub.PublishTime, time.Date(2024, 7, 3, 12, 34, 56, 789000000, time.UTC))
+
+		data, ok := input.PubSub.Data.(map[string]any)
+		gt.True(t, ok)
+		gt.Equal(t, data["name"], any("report.csv"))
+
+		// Raw envelope is kept as body for compatibility
+		body, ok := input.Body.(map[string]any)
+		gt.True(t, ok)
+		gt.Equal(t, body["subscription"], any("projects/my-project/subscriptions/nounify"))
+	})
+
+	t.Run("data that is not JSON is a string", func(t *testing.T) {
+		body := []byte(`{"message":{"data":"aGVsbG8=","messageId":"1"},"subscription":"projects/p/subscriptions/s"}`)
+		code, input := handle(t, newReq(body))
+		gt.Equal(t, code, http.StatusOK)
+		gt.Equal(t, input.PubSub.Data, any("hello"))
+	})
+
+	t.Run("not an envelope", func(t *testing.T) {
+		code, input := handle(t, newReq([]byte(`{"message":"hello"}`)))
+		gt.Equal(t, code, http.StatusOK)
+		gt.Equal(t, input.PubSub, nil)
+	})
+
+	t.Run("invalid data", func(t *testing.T) {
+		body := []byte(`{"message":{"data":"!!!","messageId":"1"},"subscription":"projects/p/subscriptions/s"}`)
+		code, _ := handle(t, newReq(body))
+		gt.Equal(t, code, http.StatusBadRequest)
+	})
+
+	t.Run("ID token is required", func(t *testing.T) {
+		code, input := handle(t, newReq(pubsubPush), server.WithPubSubIDTokenRequired())
+		gt.Equal(t, code, http.StatusOK)
+		gt.Equal(t, input.PubSub, nil)
+	})
+}
+
+func TestDeliveryReport(t *testing.T) {
+	testCases := map[string]struct {
+		results []model.DeliveryResult
+		code    int
+	}{
+		"partial failure": {
+			results: []model.DeliveryResult{
+				{Channel: "ch1", Status: types.DeliverySucceeded},
+				{Channel: "ch2", Status: types.DeliveryFailed, Error: "channel_not_found"},
+			},
+			code: http.StatusMultiStatus,
+		},
+		"partial failure with queued message": {
+			results: []model.DeliveryResult{
+				{Channel: "ch1", Status: types.DeliveryQueued},
+				{Channel: "ch2", Status: types.DeliveryFailed, Error: "channel_not_found"},
+			},
+			code: http.StatusMultiStatus,
+		},
+		"all failed": {
+			results: []model.DeliveryResult{
+				{Channel: "ch1", Status: types.DeliverySuppressed},
+				{Channel: "ch2", Status: types.DeliveryFailed, Error: "channel_not_found"},
+			},
+			code: http.StatusInternalServerError,
+		},
+	}
+
+	for title, tc := range testCases {
+		t.Run(title, func(t *testing.T) {
+			ucMock := mock.UseCasesMock{
+				HandleMessageFunc: func(ctx context.Context, schema types.Schema, input *model.MessageQueryInput) (*model.DeliveryReport, error) {
+					return &model.DeliveryReport{Results: tc.results}, types.ErrDeliveryFailed
+				},
+			}
+			w := httptest.NewRecorder()
+
+			r := httptest.NewRequest(http.MethodPost, "/msg/schema", bytes.NewReader([]byte("{}")))
+			r.Header.Set("Content-Type", "application/json")
+			mux := server.New(context.Background(), &ucMock)
+			mux.ServeHTTP(w, r)
+
+			gt.Equal(t, w.Code, tc.code)
+			gt.Equal(t, w.Header().Get("Content-Type"), "application/json")
+
+			var report model.DeliveryReport
+			gt.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+			gt.A(t, report.Results).Length(2)
+			gt.Equal(t, report.Results[1].Channel, "ch2")
+			gt.Equal(t, report.Results[1].Status, types.DeliveryFailed)
+		})
+	}
+}
+
+func TestAsyncAccepted(t *testing.T) {
+	for _, status := range []types.DeliveryStatus{types.DeliveryQueued, types.DeliveryBuffered} {
+		t.Run(string(status), func(t *testing.T) {
+			ucMock := mock.UseCasesMock{
+				HandleMessageFunc: func(ctx context.Context, schema types.Schema, input *model.MessageQueryInput) (*model.DeliveryReport, error) {
+					return &model.DeliveryReport{
+						Results: []model.DeliveryResult{
+							{Channel: "ch1", Status: status},
+						},
+					}, nil
+				},
+			}
+			w := httptest.NewRecorder()
+
+			r := httptest.NewRequest(http.MethodPost, "/msg/schema", bytes.NewReader([]byte("{}")))
+			r.Header.Set("Content-Type", "application/json")
+			mux := server.New(context.Background(), &ucMock)
+			mux.ServeHTTP(w, r)
+
+			gt.Equal(t, w.Code, http.StatusAccepted)
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+	"github.com/m-mizutani/nounify/pkg/domain/types"
+	"github.com/m-mizutani/nounify/pkg/infra/httpclient"
+	"github.com/m-mizutani/nounify/pkg/utils/ctxutil"
+)
+
+// decodeSNS decodes a message of Amazon SNS. Message is parsed as JSON (e.g. CloudWatch alarm and EventBridge event) if possible.
+func decodeSNS(body []byte) (*model.InputSNS, error) {
+	var msg snsMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, goerr.Wrap(types.ErrInvalidInput.Wrap(err), "invalid SNS message")
+	}
+
+	input := &model.InputSNS{
+		Type:              msg.Type,
+		MessageId:         msg.MessageId,
+		TopicArn:          msg.TopicArn,
+		Subject:           msg.Subject,
+		Message:           msg.Message,
+		Timestamp:         msg.Timestamp,
+		MessageAttributes: msg.MessageAttributes,
+	}
+
+	var data any
+	if err := json.Unmarshal([]byte(msg.Message), &data); err == nil {
+		input.Message = data
+	}
+
+	return input, nil
+}
+
+// snsHostPattern matches hosts of Amazon SNS endpoints in the aws partition, e.g. sns.us-east-1.amazonaws.com. China regions (amazonaws.com.cn) are not supported because their signing certificates are not issued to snsCertSubject.
+var snsHostPattern = regexp.MustCompile(`^sns\.[a-z0-9-]+\.amazonaws\.com$`)
+
+// validateSNSURL checks that the URL of SigningCertURL or SubscribeURL points to Amazon SNS
+// Only scheme and host are put into errors because query of SubscribeURL has the confirmation token.
+func validateSNSURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		// url.Error has the whole URL in its message, then keep only the cause
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, goerr.Wrap(err, "invalid URL")
+	}
+	if u.Scheme != "https" || u.User != nil || !snsHostPattern.MatchString(u.Host) {
+		return nil, goerr.New("unacceptable URL").With("scheme", u.Scheme).With("host", u.Host)
+	}
+	return u, nil
+}
+
+// snsConfirmer confirms SNS subscriptions of allowed topics by visiting SubscribeURL
+type snsConfirmer struct {
+	topics []string
+	client *http.Client
+}
+
+func newSNSConfirmer(topics []string) *snsConfirmer {
+	return &snsConfirmer{
+		topics: topics,
+		client: httpclient.New(),
+	}
+}
+
+// allowed returns true if the topic ARN matches one of the patterns. A pattern can have wildcard, e.g. arn:aws:sns:*:123456789012:*
+func (x *snsConfirmer) allowed(topicArn string) bool {
+	for _, pattern := range x.topics {
+		if ok, err := path.Match(pattern, topicArn); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// confirmAwsSNS confirms verified SubscriptionConfirmation messages of allowed topics. It must be used after authWithPolicy, then a subscription is confirmed only if the auth policy also allows the request.
+func confirmAwsSNS(confirmer *snsConfirmer) middlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			auth := ctxutil.AwsSNSAuth(r.Context())
+			if auth != nil && auth.Type == "SubscriptionConfirmation" {
+				confirmed, err := confirmer.handle(r)
+				if err != nil {
+					// SNS retries the confirmation message on error
+					handleError(r.Context(), w, err)
+					return
+				}
+				auth.SubscriptionConfirmed = confirmed
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+type snsConfirmResponse struct {
+	SubscriptionArn string `xml:"ConfirmSubscriptionResult>SubscriptionArn"`
+}
+
+// handle confirms the subscription if the topic is allowed. The message must have been verified. It returns true if the subscription is confirmed.
+func (x *snsConfirmer) handle(r *http.Request) (bool, error) {
+	ctx := r.Context()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return false, goerr.Wrap(err, "failed to read request body")
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body)) // refill the body
+
+	var msg snsMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return false, goerr.Wrap(err, "invalid JSON format").With("body", string(body))
+	}
+
+	if !x.allowed(msg.TopicArn) {
+		ctxutil.Logger(ctx).Warn("SNS subscription of not allowed topic, skip confirmation",
+			"topic_arn", msg.TopicArn,
+			"message_id", msg.MessageId,
+		)
+		return false, nil
+	}
+
+	subscriptionArn, err := x.confirm(ctx, msg.SubscribeURL)
+	if err != nil {
+		return false, goerr.Wrap(err).With("topic_arn", msg.TopicArn).With("message_id", msg.MessageId)
+	}
+
+	ctxutil.Logger(ctx).Info("confirmed SNS subscription",
+		"topic_arn", msg.TopicArn,
+		"subscription_arn", subscriptionArn,
+		"message_id", msg.MessageId,
+		"remote_addr", r.RemoteAddr,
+	)
+	return true, nil
+}
+
+func (x *snsConfirmer) confirm(ctx context.Context, subscribeURL string) (string, error) {
+	u, err := validateSNSURL(subscribeURL)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", goerr.Wrap(err, "failed to create request")
+	}
+
+	// SubscribeURL has the token, then it should not be in errors
+	data, err := httpclient.Send(x.client, req)
+	if err != nil {
+		return "", goerr.Wrap(err, "failed to confirm SNS subscription").With("host", u.Host)
+	}
+
+	var resp snsConfirmResponse
+	if err := xml.Unmarshal(data, &resp); err != nil {
+		return "", goerr.Wrap(err, "failed to parse response of SNS subscription confirmation").With("body", string(data))
+	}
+
+	return resp.SubscriptionArn, nil
+}
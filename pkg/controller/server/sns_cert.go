@@ -0,0 +1,190 @@
+package server
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"expvar"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/nounify/pkg/infra/httpclient"
+)
+
+// snsCertMaxAge is the maximum period to cache a signing certificate even if NotAfter is later, to pick up rotated certificates
+const snsCertMaxAge = 24 * time.Hour
+
+// snsCertMetrics counts hit, miss and error of the signing certificate cache. It is published by expvar and served by admin API.
+var snsCertMetrics = expvar.NewMap("nounify_sns_cert_cache")
+
+type snsCertEntry struct {
+	done      chan struct{}
+	cert      *x509.Certificate
+	err       error
+	expiresAt time.Time
+}
+
+// snsCertCache caches signing certificates of SNS by URL. Concurrent requests for the same URL share one fetch.
+type snsCertCache struct {
+	client  *http.Client
+	roots   *x509.CertPool
+	now     func() time.Time
+	entries map[string]*snsCertEntry
+	mutex   sync.Mutex
+}
+
+func newSNSCertCache(client *http.Client) *snsCertCache {
+	return &snsCertCache{
+		client:  client,
+		now:     time.Now,
+		entries: make(map[string]*snsCertEntry),
+	}
+}
+
+var defaultSNSCertCache = newSNSCertCache(httpclient.New())
+
+func (x *snsCertCache) get(ctx context.Context, certURL string) (*x509.Certificate, error) {
+	x.mutex.Lock()
+	entry, ok := x.entries[certURL]
+	if ok {
+		select {
+		case <-entry.done:
+			if entry.err == nil && x.now().After(entry.expiresAt) {
+				ok = false
+			}
+		default:
+			// Fetching by another request
+		}
+	}
+	if !ok {
+		entry = &snsCertEntry{done: make(chan struct{})}
+		x.entries[certURL] = entry
+		x.mutex.Unlock()
+
+		snsCertMetrics.Add("miss", 1)
+		x.fetch(ctx, certURL, entry)
+	} else {
+		x.mutex.Unlock()
+		snsCertMetrics.Add("hit", 1)
+	}
+
+	select {
+	case <-entry.done:
+	case <-ctx.Done():
+		return nil, goerr.Wrap(ctx.Err(), "canceled to wait for certificate").With("url", certURL)
+	}
+
+	if entry.err != nil {
+		return nil, entry.err
+	}
+	return entry.cert, nil
+}
+
+func (x *snsCertCache) fetch(ctx context.Context, certURL string, entry *snsCertEntry) {
+	defer close(entry.done)
+
+	// Detach from the request so that waiting requests are not affected by cancellation of the first one
+	cert, err := fetchAWSCert(context.WithoutCancel(ctx), x.client, x.roots, x.now(), certURL)
+	if err != nil {
+		snsCertMetrics.Add("error", 1)
+		entry.err = err
+
+		// Do not cache the failure, next request fetches the certificate again
+		x.mutex.Lock()
+		if x.entries[certURL] == entry {
+			delete(x.entries, certURL)
+		}
+		x.mutex.Unlock()
+		return
+	}
+
+	entry.cert = cert
+	entry.expiresAt = x.now().Add(snsCertMaxAge)
+	if cert.NotAfter.Before(entry.expiresAt) {
+		entry.expiresAt = cert.NotAfter
+	}
+}
+
+func fetchAWSCert(ctx context.Context, client *http.Client, roots *x509.CertPool, now time.Time, certURL string) (*x509.Certificate, error) {
+	u, err := validateSNSURL(certURL)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(u.Path, ".pem") {
+		return nil, goerr.New("unacceptable certificate path").With("url", certURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create request").With("url", certURL)
+	}
+
+	certData, err := httpclient.Send(client, req)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to fetch certificate").With("url", certURL)
+	}
+
+	var certs []*x509.Certificate
+	for rest := certData; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to parse certificate").With("url", certURL)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, goerr.New("failed to decode PEM block").With("url", certURL)
+	}
+
+	if err := verifySNSCert(certs, roots, now); err != nil {
+		return nil, goerr.Wrap(err).With("url", certURL)
+	}
+
+	return certs[0], nil
+}
+
+// snsCertSubject is the subject common name of signing certificates of SNS in regions matched by snsHostPattern
+const snsCertSubject = "sns.amazonaws.com"
+
+// verifySNSCert checks the subject and validity period of the signing certificate. If the PEM has intermediate certificates, the chain is verified up to the roots (system roots if nil). AWS serves only the signing certificate and Go does not fetch intermediates, then such a certificate is trusted by the subject and TLS connection to the SNS host.
+func verifySNSCert(certs []*x509.Certificate, roots *x509.CertPool, now time.Time) error {
+	leaf := certs[0]
+	if leaf.Subject.CommonName != snsCertSubject {
+		return goerr.New("certificate is not issued to SNS").With("subject", leaf.Subject.String())
+	}
+	if now.Before(leaf.NotBefore) || now.After(leaf.NotAfter) {
+		return goerr.New("certificate is not valid at this time").
+			With("not_before", leaf.NotBefore).
+			With("not_after", leaf.NotAfter)
+	}
+
+	if len(certs) == 1 {
+		return nil
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	if _, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}); err != nil {
+		return goerr.Wrap(err, "failed to verify certificate chain").With("subject", leaf.Subject.String())
+	}
+
+	return nil
+}
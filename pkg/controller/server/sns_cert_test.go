@@ -0,0 +1,188 @@
+package server_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/nounify/pkg/controller/server"
+)
+
+const snsCertURL = "https://sns.us-east-1.amazonaws.com/SimpleNotificationService-0123456789abcdef.pem"
+
+func TestValidateSNSURL(t *testing.T) {
+	testCases := map[string]bool{
+		"https://sns.us-east-1.amazonaws.com/SimpleNotificationService-abc.pem":     true,
+		"https://sns.cn-north-1.amazonaws.com.cn/SimpleNotificationService-abc.pem": false,
+		"http://sns.us-east-1.amazonaws.com/SimpleNotificationService-abc.pem":      false,
+		"https://s3.us-east-1.amazonaws.com/bucket/cert.pem":                        false,
+		"https://attacker.amazonaws.com/cert.pem":                                   false,
+		"https://sns.us-east-1.amazonaws.com.attacker.com/cert.pem":                 false,
+		"https://sns.us-east-1.amazonaws.com:8443/cert.pem":                         false,
+		"https://user@sns.us-east-1.amazonaws.com/cert.pem":                         false,
+	}
+
+	for rawURL, valid := range testCases {
+		t.Run(rawURL, func(t *testing.T) {
+			_, err := server.ValidateSNSURL(rawURL)
+			gt.Equal(t, err == nil, valid)
+		})
+	}
+
+	t.Run("token of SubscribeURL is not in error", func(t *testing.T) {
+		for _, rawURL := range []string{
+			"https://attacker.example.com/?Action=ConfirmSubscription&Token=secret-token",
+			"https://sns.us-east-1.amazonaws.com/?Action=ConfirmSubscription&Token=secret-token\x7f",
+		} {
+			_, err := server.ValidateSNSURL(rawURL)
+			gt.Error(t, err)
+			gt.S(t, fmt.Sprintf("%+v", err)).NotContains("secret-token")
+		}
+	})
+}
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *rsa.PrivateKey
+}
+
+func newTestCert(t *testing.T, cn string, notAfter time.Time, parent *testCert) *testCert {
+	t.Helper()
+	key := gt.R1(rsa.GenerateKey(rand.Reader, 2048)).NoError(t)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  parent == nil,
+	}
+
+	signer, signerKey := tmpl, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der := gt.R1(x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)).NoError(t)
+	return &testCert{cert: gt.R1(x509.ParseCertificate(der)).NoError(t), key: key}
+}
+
+func encodePEM(certs ...*testCert) string {
+	var b strings.Builder
+	for _, c := range certs {
+		_ = pem.Encode(&b, &pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw})
+	}
+	return b.String()
+}
+
+func TestSNSCertCache(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	current := now
+	clock := func() time.Time { return current }
+
+	newClient := func(body string, calls *int) *http.Client {
+		return &http.Client{
+			Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				*calls++
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader(body)),
+					Header:     http.Header{},
+				}, nil
+			}),
+		}
+	}
+
+	t.Run("cache certificate until max age", func(t *testing.T) {
+		leaf := newTestCert(t, "sns.amazonaws.com", now.Add(365*24*time.Hour), nil)
+		var calls int
+		cache := server.NewSNSCertCache(newClient(encodePEM(leaf), &calls), nil, clock)
+		hit, miss := server.SNSCertMetric("hit"), server.SNSCertMetric("miss")
+
+		cert := gt.R1(cache.Get(ctx, snsCertURL)).NoError(t)
+		gt.Equal(t, cert.Subject.CommonName, "sns.amazonaws.com")
+		gt.R1(cache.Get(ctx, snsCertURL)).NoError(t)
+		gt.Equal(t, calls, 1)
+		gt.Equal(t, server.SNSCertMetric("hit"), hit+1)
+		gt.Equal(t, server.SNSCertMetric("miss"), miss+1)
+
+		current = now.Add(25 * time.Hour)
+		defer func() { current = now }()
+		gt.R1(cache.Get(ctx, snsCertURL)).NoError(t)
+		gt.Equal(t, calls, 2)
+	})
+
+	t.Run("expire certificate by NotAfter", func(t *testing.T) {
+		leaf := newTestCert(t, "sns.amazonaws.com", now.Add(time.Hour), nil)
+		var calls int
+		cache := server.NewSNSCertCache(newClient(encodePEM(leaf), &calls), nil, clock)
+		gt.R1(cache.Get(ctx, snsCertURL)).NoError(t)
+
+		// The cached certificate is expired, then fetched again and rejected
+		current = now.Add(2 * time.Hour)
+		defer func() { current = now }()
+		_, err := cache.Get(ctx, snsCertURL)
+		gt.Error(t, err)
+		gt.Equal(t, calls, 2)
+	})
+
+	t.Run("error is not cached", func(t *testing.T) {
+		var calls int
+		cache := server.NewSNSCertCache(newClient("not a certificate", &calls), nil, clock)
+		errors := server.SNSCertMetric("error")
+
+		_, err := cache.Get(ctx, snsCertURL)
+		gt.Error(t, err)
+		_, err = cache.Get(ctx, snsCertURL)
+		gt.Error(t, err)
+		gt.Equal(t, calls, 2)
+		gt.Equal(t, server.SNSCertMetric("error"), errors+2)
+	})
+
+	t.Run("reject URL of other host", func(t *testing.T) {
+		var calls int
+		cache := server.NewSNSCertCache(newClient("", &calls), nil, clock)
+		_, err := cache.Get(ctx, "https://attacker.example.com/cert.pem")
+		gt.Error(t, err)
+		gt.Equal(t, calls, 0)
+	})
+
+	t.Run("reject certificate not issued to SNS", func(t *testing.T) {
+		leaf := newTestCert(t, "attacker.example.com", now.Add(30*24*time.Hour), nil)
+		var calls int
+		cache := server.NewSNSCertCache(newClient(encodePEM(leaf), &calls), nil, clock)
+		_, err := cache.Get(ctx, snsCertURL)
+		gt.Error(t, err)
+	})
+
+	t.Run("verify certificate chain", func(t *testing.T) {
+		root := newTestCert(t, "Test Root CA", now.Add(365*24*time.Hour), nil)
+		leaf := newTestCert(t, "sns.amazonaws.com", now.Add(30*24*time.Hour), root)
+		other := newTestCert(t, "Other Root CA", now.Add(365*24*time.Hour), nil)
+
+		roots := x509.NewCertPool()
+		roots.AddCert(root.cert)
+
+		var calls int
+		cache := server.NewSNSCertCache(newClient(encodePEM(leaf, root), &calls), roots, clock)
+		gt.R1(cache.Get(ctx, snsCertURL)).NoError(t)
+
+		untrusted := x509.NewCertPool()
+		untrusted.AddCert(other.cert)
+		cache = server.NewSNSCertCache(newClient(encodePEM(leaf, root), &calls), untrusted, clock)
+		_, err := cache.Get(ctx, snsCertURL)
+		gt.Error(t, err)
+	})
+}
@@ -0,0 +1,158 @@
+package server_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/nounify/pkg/controller/server"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+	"github.com/m-mizutani/nounify/pkg/utils/ctxutil"
+	"github.com/m-mizutani/opac"
+)
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+const snsConfirmResponse = `<ConfirmSubscriptionResponse xmlns="http://sns.amazonaws.com/doc/2010-03-31/">
+  <ConfirmSubscriptionResult>
+    <SubscriptionArn>arn:aws:sns:ap-northeast-1:783957204773:nounify-test:158bcac4</SubscriptionArn>
+  </ConfirmSubscriptionResult>
+</ConfirmSubscriptionResponse>`
+
+func TestSNSConfirmer(t *testing.T) {
+	type testCase struct {
+		topics    []string
+		body      func() []byte
+		status    int
+		confirmed bool
+		requested bool
+		err       bool
+	}
+
+	runTest := func(tc testCase) func(t *testing.T) {
+		return func(t *testing.T) {
+			var requested *http.Request
+			client := &http.Client{
+				Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+					requested = req
+					return &http.Response{
+						StatusCode: tc.status,
+						Body:       io.NopCloser(strings.NewReader(snsConfirmResponse)),
+						Header:     http.Header{},
+					}, nil
+				}),
+			}
+
+			body := tc.body()
+			r := httptest.NewRequest(http.MethodPost, "/msg/sns", bytes.NewReader(body))
+			confirmed, err := server.NewSNSConfirmer(tc.topics, client).Handle(r)
+			if tc.err {
+				gt.Error(t, err)
+			} else {
+				gt.NoError(t, err)
+			}
+			gt.Equal(t, confirmed, tc.confirmed)
+			gt.Equal(t, requested != nil, tc.requested)
+			if requested != nil {
+				gt.Equal(t, requested.URL.Host, "sns.ap-northeast-1.amazonaws.com")
+				gt.Equal(t, requested.URL.Query().Get("Action"), "ConfirmSubscription")
+			}
+
+			// Body is kept for following handlers
+			gt.Equal(t, gt.R1(io.ReadAll(r.Body)).NoError(t), body)
+		}
+	}
+
+	t.Run("confirm allowed topic", runTest(testCase{
+		topics:    []string{"arn:aws:sns:ap-northeast-1:783957204773:nounify-test"},
+		body:      func() []byte { return awsSNSSubscribe },
+		status:    http.StatusOK,
+		confirmed: true,
+		requested: true,
+	}))
+
+	t.Run("confirm topic matched with wildcard", runTest(testCase{
+		topics:    []string{"arn:aws:sns:*:783957204773:*"},
+		body:      func() []byte { return awsSNSSubscribe },
+		status:    http.StatusOK,
+		confirmed: true,
+		requested: true,
+	}))
+
+	t.Run("skip not allowed topic", runTest(testCase{
+		topics: []string{"arn:aws:sns:ap-northeast-1:111111111111:*"},
+		body:   func() []byte { return awsSNSSubscribe },
+		status: http.StatusOK,
+	}))
+
+	t.Run("error response", runTest(testCase{
+		topics:    []string{"*"},
+		body:      func() []byte { return awsSNSSubscribe },
+		status:    http.StatusForbidden,
+		requested: true,
+		err:       true,
+	}))
+
+	t.Run("reject SubscribeURL of other host", runTest(testCase{
+		topics: []string{"*"},
+		body: func() []byte {
+			return bytes.ReplaceAll(awsSNSSubscribe,
+				[]byte("https://sns.ap-northeast-1.amazonaws.com/"),
+				[]byte("https://attacker.example.com/"))
+		},
+		status: http.StatusOK,
+		err:    true,
+	}))
+}
+
+func TestConfirmAwsSNSAfterAuthPolicy(t *testing.T) {
+	policy := gt.R1(opac.New(opac.Data(map[string]string{"auth": `package auth
+
+allow {
+	input.auth.aws.sns.TopicArn == "arn:aws:sns:ap-northeast-1:783957204773:nounify-test"
+	not input.auth.aws.sns.SubscriptionConfirmed
+}`}))).NoError(t)
+
+	runTest := func(topicArn string, status int, confirmed bool) func(t *testing.T) {
+		return func(t *testing.T) {
+			var requested bool
+			client := &http.Client{
+				Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+					requested = true
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       io.NopCloser(strings.NewReader(snsConfirmResponse)),
+						Header:     http.Header{},
+					}, nil
+				}),
+			}
+
+			auth := &model.AwsSNSAuth{Type: "SubscriptionConfirmation", TopicArn: topicArn}
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+			confirmer := server.NewSNSConfirmer([]string{"*"}, client)
+			handler := server.AuthWithPolicy(policy, http.StatusForbidden)(server.ConfirmAwsSNS(confirmer)(next))
+
+			r := httptest.NewRequest(http.MethodPost, "/msg/sns", bytes.NewReader(awsSNSSubscribe))
+			r = r.WithContext(ctxutil.WithAwsSNSAuth(r.Context(), auth))
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			gt.Equal(t, w.Code, status)
+			gt.Equal(t, requested, confirmed)
+			gt.Equal(t, auth.SubscriptionConfirmed, confirmed)
+		}
+	}
+
+	t.Run("confirm subscription allowed by policy", runTest("arn:aws:sns:ap-northeast-1:783957204773:nounify-test", http.StatusOK, true))
+	t.Run("do not confirm subscription denied by policy", runTest("arn:aws:sns:ap-northeast-1:111111111111:other", http.StatusForbidden, false))
+}
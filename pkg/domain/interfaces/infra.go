@@ -2,15 +2,54 @@ package interfaces
 
 import (
 	"context"
+	"time"
 
+	"github.com/m-mizutani/nounify/pkg/domain/model"
 	"github.com/m-mizutani/opac"
 	"github.com/slack-go/slack"
 )
 
 type Slack interface {
 	PostMessageContext(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error)
+	UpdateMessageContext(ctx context.Context, channelID, timestamp string, options ...slack.MsgOption) (string, string, string, error)
+	UploadFileV2Context(ctx context.Context, params slack.UploadFileV2Parameters) (*slack.FileSummary, error)
+}
+
+// Notifier delivers a message without Slack API, e.g. Microsoft Teams or Slack incoming webhook. The channel of the message is a name of the target configured in the notifier.
+type Notifier interface {
+	Notify(ctx context.Context, msg model.Message) error
 }
 
 type Policy interface {
 	Query(ctx context.Context, query string, input, output any, options ...opac.QueryOption) error
 }
+
+// Outbox persists messages before delivery to guarantee at-least-once delivery.
+type Outbox interface {
+	Put(ctx context.Context, d *model.Delivery) error
+	Delete(ctx context.Context, id string) error
+	List(ctx context.Context) ([]*model.Delivery, error)
+}
+
+// DeadLetterStore keeps messages that failed to be delivered for investigation and redelivery.
+type DeadLetterStore interface {
+	Put(ctx context.Context, dl *model.DeadLetter) error
+	// Get returns nil if the dead letter is not found
+	Get(ctx context.Context, id string) (*model.DeadLetter, error)
+	List(ctx context.Context) ([]*model.DeadLetter, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// DedupStore records keys for a period to detect duplicated requests. Implement it with a shared backend to deduplicate across multiple instances.
+type DedupStore interface {
+	// Add records key for ttl. It returns false if the key has been recorded already and not expired.
+	Add(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	Remove(ctx context.Context, key string) error
+}
+
+// MessageRefStore maps a key from policy (e.g. thread_key) to a message posted to Slack.
+type MessageRefStore interface {
+	// Get returns nil if the key is not found
+	Get(ctx context.Context, key string) (*model.MessageRef, error)
+	Put(ctx context.Context, key string, ref *model.MessageRef) error
+}
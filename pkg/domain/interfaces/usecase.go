@@ -8,5 +8,11 @@ import (
 )
 
 type UseCases interface {
-	HandleMessage(ctx context.Context, schema types.Schema, input *model.MessageQueryInput) error
+	HandleMessage(ctx context.Context, schema types.Schema, input *model.MessageQueryInput) (*model.DeliveryReport, error)
+
+	ListDeadLetters(ctx context.Context) ([]*model.DeadLetter, error)
+	GetDeadLetter(ctx context.Context, id string) (*model.DeadLetter, error)
+	RedeliverDeadLetter(ctx context.Context, id string) (*model.DeliveryReport, error)
+	DeleteDeadLetter(ctx context.Context, id string) error
+	PurgeDeadLetters(ctx context.Context) (int, error)
 }
@@ -6,9 +6,11 @@ package mock
 import (
 	"context"
 	"github.com/m-mizutani/nounify/pkg/domain/interfaces"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
 	"github.com/m-mizutani/opac"
 	"github.com/slack-go/slack"
 	"sync"
+	"time"
 )
 
 // Ensure, that SlackMock does implement interfaces.Slack.
@@ -24,6 +26,12 @@ var _ interfaces.Slack = &SlackMock{}
 //			PostMessageContextFunc: func(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
 //				panic("mock out the PostMessageContext method")
 //			},
+//			UpdateMessageContextFunc: func(ctx context.Context, channelID string, timestamp string, options ...slack.MsgOption) (string, string, string, error) {
+//				panic("mock out the UpdateMessageContext method")
+//			},
+//			UploadFileV2ContextFunc: func(ctx context.Context, params slack.UploadFileV2Parameters) (*slack.FileSummary, error) {
+//				panic("mock out the UploadFileV2Context method")
+//			},
 //		}
 //
 //		// use mockedSlack in code that requires interfaces.Slack
@@ -34,6 +42,12 @@ type SlackMock struct {
 	// PostMessageContextFunc mocks the PostMessageContext method.
 	PostMessageContextFunc func(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error)
 
+	// UpdateMessageContextFunc mocks the UpdateMessageContext method.
+	UpdateMessageContextFunc func(ctx context.Context, channelID string, timestamp string, options ...slack.MsgOption) (string, string, string, error)
+
+	// UploadFileV2ContextFunc mocks the UploadFileV2Context method.
+	UploadFileV2ContextFunc func(ctx context.Context, params slack.UploadFileV2Parameters) (*slack.FileSummary, error)
+
 	// calls tracks calls to the methods.
 	calls struct {
 		// PostMessageContext holds details about calls to the PostMessageContext method.
@@ -45,8 +59,28 @@ type SlackMock struct {
 			// Options is the options argument value.
 			Options []slack.MsgOption
 		}
+		// UpdateMessageContext holds details about calls to the UpdateMessageContext method.
+		UpdateMessageContext []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ChannelID is the channelID argument value.
+			ChannelID string
+			// Timestamp is the timestamp argument value.
+			Timestamp string
+			// Options is the options argument value.
+			Options []slack.MsgOption
+		}
+		// UploadFileV2Context holds details about calls to the UploadFileV2Context method.
+		UploadFileV2Context []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Params is the params argument value.
+			Params slack.UploadFileV2Parameters
+		}
 	}
-	lockPostMessageContext sync.RWMutex
+	lockPostMessageContext   sync.RWMutex
+	lockUpdateMessageContext sync.RWMutex
+	lockUploadFileV2Context  sync.RWMutex
 }
 
 // PostMessageContext calls PostMessageContextFunc.
@@ -89,6 +123,158 @@ func (mock *SlackMock) PostMessageContextCalls() []struct {
 	return calls
 }
 
+// UpdateMessageContext calls UpdateMessageContextFunc.
+func (mock *SlackMock) UpdateMessageContext(ctx context.Context, channelID string, timestamp string, options ...slack.MsgOption) (string, string, string, error) {
+	if mock.UpdateMessageContextFunc == nil {
+		panic("SlackMock.UpdateMessageContextFunc: method is nil but Slack.UpdateMessageContext was just called")
+	}
+	callInfo := struct {
+		Ctx       context.Context
+		ChannelID string
+		Timestamp string
+		Options   []slack.MsgOption
+	}{
+		Ctx:       ctx,
+		ChannelID: channelID,
+		Timestamp: timestamp,
+		Options:   options,
+	}
+	mock.lockUpdateMessageContext.Lock()
+	mock.calls.UpdateMessageContext = append(mock.calls.UpdateMessageContext, callInfo)
+	mock.lockUpdateMessageContext.Unlock()
+	return mock.UpdateMessageContextFunc(ctx, channelID, timestamp, options...)
+}
+
+// UpdateMessageContextCalls gets all the calls that were made to UpdateMessageContext.
+// Check the length with:
+//
+//	len(mockedSlack.UpdateMessageContextCalls())
+func (mock *SlackMock) UpdateMessageContextCalls() []struct {
+	Ctx       context.Context
+	ChannelID string
+	Timestamp string
+	Options   []slack.MsgOption
+} {
+	var calls []struct {
+		Ctx       context.Context
+		ChannelID string
+		Timestamp string
+		Options   []slack.MsgOption
+	}
+	mock.lockUpdateMessageContext.RLock()
+	calls = mock.calls.UpdateMessageContext
+	mock.lockUpdateMessageContext.RUnlock()
+	return calls
+}
+
+// UploadFileV2Context calls UploadFileV2ContextFunc.
+func (mock *SlackMock) UploadFileV2Context(ctx context.Context, params slack.UploadFileV2Parameters) (*slack.FileSummary, error) {
+	if mock.UploadFileV2ContextFunc == nil {
+		panic("SlackMock.UploadFileV2ContextFunc: method is nil but Slack.UploadFileV2Context was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Params slack.UploadFileV2Parameters
+	}{
+		Ctx:    ctx,
+		Params: params,
+	}
+	mock.lockUploadFileV2Context.Lock()
+	mock.calls.UploadFileV2Context = append(mock.calls.UploadFileV2Context, callInfo)
+	mock.lockUploadFileV2Context.Unlock()
+	return mock.UploadFileV2ContextFunc(ctx, params)
+}
+
+// UploadFileV2ContextCalls gets all the calls that were made to UploadFileV2Context.
+// Check the length with:
+//
+//	len(mockedSlack.UploadFileV2ContextCalls())
+func (mock *SlackMock) UploadFileV2ContextCalls() []struct {
+	Ctx    context.Context
+	Params slack.UploadFileV2Parameters
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Params slack.UploadFileV2Parameters
+	}
+	mock.lockUploadFileV2Context.RLock()
+	calls = mock.calls.UploadFileV2Context
+	mock.lockUploadFileV2Context.RUnlock()
+	return calls
+}
+
+// Ensure, that NotifierMock does implement interfaces.Notifier.
+// If this is not the case, regenerate this file with moq.
+var _ interfaces.Notifier = &NotifierMock{}
+
+// NotifierMock is a mock implementation of interfaces.Notifier.
+//
+//	func TestSomethingThatUsesNotifier(t *testing.T) {
+//
+//		// make and configure a mocked interfaces.Notifier
+//		mockedNotifier := &NotifierMock{
+//			NotifyFunc: func(ctx context.Context, msg model.Message) error {
+//				panic("mock out the Notify method")
+//			},
+//		}
+//
+//		// use mockedNotifier in code that requires interfaces.Notifier
+//		// and then make assertions.
+//
+//	}
+type NotifierMock struct {
+	// NotifyFunc mocks the Notify method.
+	NotifyFunc func(ctx context.Context, msg model.Message) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Notify holds details about calls to the Notify method.
+		Notify []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Msg is the msg argument value.
+			Msg model.Message
+		}
+	}
+	lockNotify sync.RWMutex
+}
+
+// Notify calls NotifyFunc.
+func (mock *NotifierMock) Notify(ctx context.Context, msg model.Message) error {
+	if mock.NotifyFunc == nil {
+		panic("NotifierMock.NotifyFunc: method is nil but Notifier.Notify was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Msg model.Message
+	}{
+		Ctx: ctx,
+		Msg: msg,
+	}
+	mock.lockNotify.Lock()
+	mock.calls.Notify = append(mock.calls.Notify, callInfo)
+	mock.lockNotify.Unlock()
+	return mock.NotifyFunc(ctx, msg)
+}
+
+// NotifyCalls gets all the calls that were made to Notify.
+// Check the length with:
+//
+//	len(mockedNotifier.NotifyCalls())
+func (mock *NotifierMock) NotifyCalls() []struct {
+	Ctx context.Context
+	Msg model.Message
+} {
+	var calls []struct {
+		Ctx context.Context
+		Msg model.Message
+	}
+	mock.lockNotify.RLock()
+	calls = mock.calls.Notify
+	mock.lockNotify.RUnlock()
+	return calls
+}
+
 // Ensure, that PolicyMock does implement interfaces.Policy.
 // If this is not the case, regenerate this file with moq.
 var _ interfaces.Policy = &PolicyMock{}
@@ -178,3 +364,641 @@ func (mock *PolicyMock) QueryCalls() []struct {
 	mock.lockQuery.RUnlock()
 	return calls
 }
+
+// Ensure, that OutboxMock does implement interfaces.Outbox.
+// If this is not the case, regenerate this file with moq.
+var _ interfaces.Outbox = &OutboxMock{}
+
+// OutboxMock is a mock implementation of interfaces.Outbox.
+//
+//	func TestSomethingThatUsesOutbox(t *testing.T) {
+//
+//		// make and configure a mocked interfaces.Outbox
+//		mockedOutbox := &OutboxMock{
+//			DeleteFunc: func(ctx context.Context, id string) error {
+//				panic("mock out the Delete method")
+//			},
+//			ListFunc: func(ctx context.Context) ([]*model.Delivery, error) {
+//				panic("mock out the List method")
+//			},
+//			PutFunc: func(ctx context.Context, d *model.Delivery) error {
+//				panic("mock out the Put method")
+//			},
+//		}
+//
+//		// use mockedOutbox in code that requires interfaces.Outbox
+//		// and then make assertions.
+//
+//	}
+type OutboxMock struct {
+	// DeleteFunc mocks the Delete method.
+	DeleteFunc func(ctx context.Context, id string) error
+
+	// ListFunc mocks the List method.
+	ListFunc func(ctx context.Context) ([]*model.Delivery, error)
+
+	// PutFunc mocks the Put method.
+	PutFunc func(ctx context.Context, d *model.Delivery) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Delete holds details about calls to the Delete method.
+		Delete []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+		}
+		// List holds details about calls to the List method.
+		List []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+		// Put holds details about calls to the Put method.
+		Put []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// D is the d argument value.
+			D *model.Delivery
+		}
+	}
+	lockDelete sync.RWMutex
+	lockList   sync.RWMutex
+	lockPut    sync.RWMutex
+}
+
+// Delete calls DeleteFunc.
+func (mock *OutboxMock) Delete(ctx context.Context, id string) error {
+	if mock.DeleteFunc == nil {
+		panic("OutboxMock.DeleteFunc: method is nil but Outbox.Delete was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockDelete.Lock()
+	mock.calls.Delete = append(mock.calls.Delete, callInfo)
+	mock.lockDelete.Unlock()
+	return mock.DeleteFunc(ctx, id)
+}
+
+// DeleteCalls gets all the calls that were made to Delete.
+// Check the length with:
+//
+//	len(mockedOutbox.DeleteCalls())
+func (mock *OutboxMock) DeleteCalls() []struct {
+	Ctx context.Context
+	ID  string
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+	}
+	mock.lockDelete.RLock()
+	calls = mock.calls.Delete
+	mock.lockDelete.RUnlock()
+	return calls
+}
+
+// List calls ListFunc.
+func (mock *OutboxMock) List(ctx context.Context) ([]*model.Delivery, error) {
+	if mock.ListFunc == nil {
+		panic("OutboxMock.ListFunc: method is nil but Outbox.List was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockList.Lock()
+	mock.calls.List = append(mock.calls.List, callInfo)
+	mock.lockList.Unlock()
+	return mock.ListFunc(ctx)
+}
+
+// ListCalls gets all the calls that were made to List.
+// Check the length with:
+//
+//	len(mockedOutbox.ListCalls())
+func (mock *OutboxMock) ListCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockList.RLock()
+	calls = mock.calls.List
+	mock.lockList.RUnlock()
+	return calls
+}
+
+// Put calls PutFunc.
+func (mock *OutboxMock) Put(ctx context.Context, d *model.Delivery) error {
+	if mock.PutFunc == nil {
+		panic("OutboxMock.PutFunc: method is nil but Outbox.Put was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		D   *model.Delivery
+	}{
+		Ctx: ctx,
+		D:   d,
+	}
+	mock.lockPut.Lock()
+	mock.calls.Put = append(mock.calls.Put, callInfo)
+	mock.lockPut.Unlock()
+	return mock.PutFunc(ctx, d)
+}
+
+// PutCalls gets all the calls that were made to Put.
+// Check the length with:
+//
+//	len(mockedOutbox.PutCalls())
+func (mock *OutboxMock) PutCalls() []struct {
+	Ctx context.Context
+	D   *model.Delivery
+} {
+	var calls []struct {
+		Ctx context.Context
+		D   *model.Delivery
+	}
+	mock.lockPut.RLock()
+	calls = mock.calls.Put
+	mock.lockPut.RUnlock()
+	return calls
+}
+
+// Ensure, that DeadLetterStoreMock does implement interfaces.DeadLetterStore.
+// If this is not the case, regenerate this file with moq.
+var _ interfaces.DeadLetterStore = &DeadLetterStoreMock{}
+
+// DeadLetterStoreMock is a mock implementation of interfaces.DeadLetterStore.
+//
+//	func TestSomethingThatUsesDeadLetterStore(t *testing.T) {
+//
+//		// make and configure a mocked interfaces.DeadLetterStore
+//		mockedDeadLetterStore := &DeadLetterStoreMock{
+//			DeleteFunc: func(ctx context.Context, id string) error {
+//				panic("mock out the Delete method")
+//			},
+//			GetFunc: func(ctx context.Context, id string) (*model.DeadLetter, error) {
+//				panic("mock out the Get method")
+//			},
+//			ListFunc: func(ctx context.Context) ([]*model.DeadLetter, error) {
+//				panic("mock out the List method")
+//			},
+//			PutFunc: func(ctx context.Context, dl *model.DeadLetter) error {
+//				panic("mock out the Put method")
+//			},
+//		}
+//
+//		// use mockedDeadLetterStore in code that requires interfaces.DeadLetterStore
+//		// and then make assertions.
+//
+//	}
+type DeadLetterStoreMock struct {
+	// DeleteFunc mocks the Delete method.
+	DeleteFunc func(ctx context.Context, id string) error
+
+	// GetFunc mocks the Get method.
+	GetFunc func(ctx context.Context, id string) (*model.DeadLetter, error)
+
+	// ListFunc mocks the List method.
+	ListFunc func(ctx context.Context) ([]*model.DeadLetter, error)
+
+	// PutFunc mocks the Put method.
+	PutFunc func(ctx context.Context, dl *model.DeadLetter) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Delete holds details about calls to the Delete method.
+		Delete []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+		}
+		// Get holds details about calls to the Get method.
+		Get []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+		}
+		// List holds details about calls to the List method.
+		List []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+		// Put holds details about calls to the Put method.
+		Put []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Dl is the dl argument value.
+			Dl *model.DeadLetter
+		}
+	}
+	lockDelete sync.RWMutex
+	lockGet    sync.RWMutex
+	lockList   sync.RWMutex
+	lockPut    sync.RWMutex
+}
+
+// Delete calls DeleteFunc.
+func (mock *DeadLetterStoreMock) Delete(ctx context.Context, id string) error {
+	if mock.DeleteFunc == nil {
+		panic("DeadLetterStoreMock.DeleteFunc: method is nil but DeadLetterStore.Delete was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockDelete.Lock()
+	mock.calls.Delete = append(mock.calls.Delete, callInfo)
+	mock.lockDelete.Unlock()
+	return mock.DeleteFunc(ctx, id)
+}
+
+// DeleteCalls gets all the calls that were made to Delete.
+// Check the length with:
+//
+//	len(mockedDeadLetterStore.DeleteCalls())
+func (mock *DeadLetterStoreMock) DeleteCalls() []struct {
+	Ctx context.Context
+	ID  string
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+	}
+	mock.lockDelete.RLock()
+	calls = mock.calls.Delete
+	mock.lockDelete.RUnlock()
+	return calls
+}
+
+// Get calls GetFunc.
+func (mock *DeadLetterStoreMock) Get(ctx context.Context, id string) (*model.DeadLetter, error) {
+	if mock.GetFunc == nil {
+		panic("DeadLetterStoreMock.GetFunc: method is nil but DeadLetterStore.Get was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockGet.Lock()
+	mock.calls.Get = append(mock.calls.Get, callInfo)
+	mock.lockGet.Unlock()
+	return mock.GetFunc(ctx, id)
+}
+
+// GetCalls gets all the calls that were made to Get.
+// Check the length with:
+//
+//	len(mockedDeadLetterStore.GetCalls())
+func (mock *DeadLetterStoreMock) GetCalls() []struct {
+	Ctx context.Context
+	ID  string
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+	}
+	mock.lockGet.RLock()
+	calls = mock.calls.Get
+	mock.lockGet.RUnlock()
+	return calls
+}
+
+// List calls ListFunc.
+func (mock *DeadLetterStoreMock) List(ctx context.Context) ([]*model.DeadLetter, error) {
+	if mock.ListFunc == nil {
+		panic("DeadLetterStoreMock.ListFunc: method is nil but DeadLetterStore.List was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockList.Lock()
+	mock.calls.List = append(mock.calls.List, callInfo)
+	mock.lockList.Unlock()
+	return mock.ListFunc(ctx)
+}
+
+// ListCalls gets all the calls that were made to List.
+// Check the length with:
+//
+//	len(mockedDeadLetterStore.ListCalls())
+func (mock *DeadLetterStoreMock) ListCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockList.RLock()
+	calls = mock.calls.List
+	mock.lockList.RUnlock()
+	return calls
+}
+
+// Put calls PutFunc.
+func (mock *DeadLetterStoreMock) Put(ctx context.Context, dl *model.DeadLetter) error {
+	if mock.PutFunc == nil {
+		panic("DeadLetterStoreMock.PutFunc: method is nil but DeadLetterStore.Put was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Dl  *model.DeadLetter
+	}{
+		Ctx: ctx,
+		Dl:  dl,
+	}
+	mock.lockPut.Lock()
+	mock.calls.Put = append(mock.calls.Put, callInfo)
+	mock.lockPut.Unlock()
+	return mock.PutFunc(ctx, dl)
+}
+
+// PutCalls gets all the calls that were made to Put.
+// Check the length with:
+//
+//	len(mockedDeadLetterStore.PutCalls())
+func (mock *DeadLetterStoreMock) PutCalls() []struct {
+	Ctx context.Context
+	Dl  *model.DeadLetter
+} {
+	var calls []struct {
+		Ctx context.Context
+		Dl  *model.DeadLetter
+	}
+	mock.lockPut.RLock()
+	calls = mock.calls.Put
+	mock.lockPut.RUnlock()
+	return calls
+}
+
+// Ensure, that DedupStoreMock does implement interfaces.DedupStore.
+// If this is not the case, regenerate this file with moq.
+var _ interfaces.DedupStore = &DedupStoreMock{}
+
+// DedupStoreMock is a mock implementation of interfaces.DedupStore.
+//
+//	func TestSomethingThatUsesDedupStore(t *testing.T) {
+//
+//		// make and configure a mocked interfaces.DedupStore
+//		mockedDedupStore := &DedupStoreMock{
+//			AddFunc: func(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+//				panic("mock out the Add method")
+//			},
+//			RemoveFunc: func(ctx context.Context, key string) error {
+//				panic("mock out the Remove method")
+//			},
+//		}
+//
+//		// use mockedDedupStore in code that requires interfaces.DedupStore
+//		// and then make assertions.
+//
+//	}
+type DedupStoreMock struct {
+	// AddFunc mocks the Add method.
+	AddFunc func(ctx context.Context, key string, ttl time.Duration) (bool, error)
+
+	// RemoveFunc mocks the Remove method.
+	RemoveFunc func(ctx context.Context, key string) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Add holds details about calls to the Add method.
+		Add []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Key is the key argument value.
+			Key string
+			// TTL is the ttl argument value.
+			TTL time.Duration
+		}
+		// Remove holds details about calls to the Remove method.
+		Remove []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Key is the key argument value.
+			Key string
+		}
+	}
+	lockAdd    sync.RWMutex
+	lockRemove sync.RWMutex
+}
+
+// Add calls AddFunc.
+func (mock *DedupStoreMock) Add(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	if mock.AddFunc == nil {
+		panic("DedupStoreMock.AddFunc: method is nil but DedupStore.Add was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Key string
+		TTL time.Duration
+	}{
+		Ctx: ctx,
+		Key: key,
+		TTL: ttl,
+	}
+	mock.lockAdd.Lock()
+	mock.calls.Add = append(mock.calls.Add, callInfo)
+	mock.lockAdd.Unlock()
+	return mock.AddFunc(ctx, key, ttl)
+}
+
+// AddCalls gets all the calls that were made to Add.
+// Check the length with:
+//
+//	len(mockedDedupStore.AddCalls())
+func (mock *DedupStoreMock) AddCalls() []struct {
+	Ctx context.Context
+	Key string
+	TTL time.Duration
+} {
+	var calls []struct {
+		Ctx context.Context
+		Key string
+		TTL time.Duration
+	}
+	mock.lockAdd.RLock()
+	calls = mock.calls.Add
+	mock.lockAdd.RUnlock()
+	return calls
+}
+
+// Remove calls RemoveFunc.
+func (mock *DedupStoreMock) Remove(ctx context.Context, key string) error {
+	if mock.RemoveFunc == nil {
+		panic("DedupStoreMock.RemoveFunc: method is nil but DedupStore.Remove was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Key string
+	}{
+		Ctx: ctx,
+		Key: key,
+	}
+	mock.lockRemove.Lock()
+	mock.calls.Remove = append(mock.calls.Remove, callInfo)
+	mock.lockRemove.Unlock()
+	return mock.RemoveFunc(ctx, key)
+}
+
+// RemoveCalls gets all the calls that were made to Remove.
+// Check the length with:
+//
+//	len(mockedDedupStore.RemoveCalls())
+func (mock *DedupStoreMock) RemoveCalls() []struct {
+	Ctx context.Context
+	Key string
+} {
+	var calls []struct {
+		Ctx context.Context
+		Key string
+	}
+	mock.lockRemove.RLock()
+	calls = mock.calls.Remove
+	mock.lockRemove.RUnlock()
+	return calls
+}
+
+// Ensure, that MessageRefStoreMock does implement interfaces.MessageRefStore.
+// If this is not the case, regenerate this file with moq.
+var _ interfaces.MessageRefStore = &MessageRefStoreMock{}
+
+// MessageRefStoreMock is a mock implementation of interfaces.MessageRefStore.
+//
+//	func TestSomethingThatUsesMessageRefStore(t *testing.T) {
+//
+//		// make and configure a mocked interfaces.MessageRefStore
+//		mockedMessageRefStore := &MessageRefStoreMock{
+//			GetFunc: func(ctx context.Context, key string) (*model.MessageRef, error) {
+//				panic("mock out the Get method")
+//			},
+//			PutFunc: func(ctx context.Context, key string, ref *model.MessageRef) error {
+//				panic("mock out the Put method")
+//			},
+//		}
+//
+//		// use mockedMessageRefStore in code that requires interfaces.MessageRefStore
+//		// and then make assertions.
+//
+//	}
+type MessageRefStoreMock struct {
+	// GetFunc mocks the Get method.
+	GetFunc func(ctx context.Context, key string) (*model.MessageRef, error)
+
+	// PutFunc mocks the Put method.
+	PutFunc func(ctx context.Context, key string, ref *model.MessageRef) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Get holds details about calls to the Get method.
+		Get []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Key is the key argument value.
+			Key string
+		}
+		// Put holds details about calls to the Put method.
+		Put []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Key is the key argument value.
+			Key string
+			// Ref is the ref argument value.
+			Ref *model.MessageRef
+		}
+	}
+	lockGet sync.RWMutex
+	lockPut sync.RWMutex
+}
+
+// Get calls GetFunc.
+func (mock *MessageRefStoreMock) Get(ctx context.Context, key string) (*model.MessageRef, error) {
+	if mock.GetFunc == nil {
+		panic("MessageRefStoreMock.GetFunc: method is nil but MessageRefStore.Get was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Key string
+	}{
+		Ctx: ctx,
+		Key: key,
+	}
+	mock.lockGet.Lock()
+	mock.calls.Get = append(mock.calls.Get, callInfo)
+	mock.lockGet.Unlock()
+	return mock.GetFunc(ctx, key)
+}
+
+// GetCalls gets all the calls that were made to Get.
+// Check the length with:
+//
+//	len(mockedMessageRefStore.GetCalls())
+func (mock *MessageRefStoreMock) GetCalls() []struct {
+	Ctx context.Context
+	Key string
+} {
+	var calls []struct {
+		Ctx context.Context
+		Key string
+	}
+	mock.lockGet.RLock()
+	calls = mock.calls.Get
+	mock.lockGet.RUnlock()
+	return calls
+}
+
+// Put calls PutFunc.
+func (mock *MessageRefStoreMock) Put(ctx context.Context, key string, ref *model.MessageRef) error {
+	if mock.PutFunc == nil {
+		panic("MessageRefStoreMock.PutFunc: method is nil but MessageRefStore.Put was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Key string
+		Ref *model.MessageRef
+	}{
+		Ctx: ctx,
+		Key: key,
+		Ref: ref,
+	}
+	mock.lockPut.Lock()
+	mock.calls.Put = append(mock.calls.Put, callInfo)
+	mock.lockPut.Unlock()
+	return mock.PutFunc(ctx, key, ref)
+}
+
+// PutCalls gets all the calls that were made to Put.
+// Check the length with:
+//
+//	len(mockedMessageRefStore.PutCalls())
+func (mock *MessageRefStoreMock) PutCalls() []struct {
+	Ctx context.Context
+	Key string
+	Ref *model.MessageRef
+} {
+	var calls []struct {
+		Ctx context.Context
+		Key string
+		Ref *model.MessageRef
+	}
+	mock.lockPut.RLock()
+	calls = mock.calls.Put
+	mock.lockPut.RUnlock()
+	return calls
+}
@@ -21,9 +21,24 @@ var _ interfaces.UseCases = &UseCasesMock{}
 //
 //		// make and configure a mocked interfaces.UseCases
 //		mockedUseCases := &UseCasesMock{
-//			HandleMessageFunc: func(ctx context.Context, schema types.Schema, input *model.MessageQueryInput) error {
+//			DeleteDeadLetterFunc: func(ctx context.Context, id string) error {
+//				panic("mock out the DeleteDeadLetter method")
+//			},
+//			GetDeadLetterFunc: func(ctx context.Context, id string) (*model.DeadLetter, error) {
+//				panic("mock out the GetDeadLetter method")
+//			},
+//			HandleMessageFunc: func(ctx context.Context, schema types.Schema, input *model.MessageQueryInput) (*model.DeliveryReport, error) {
 //				panic("mock out the HandleMessage method")
 //			},
+//			ListDeadLettersFunc: func(ctx context.Context) ([]*model.DeadLetter, error) {
+//				panic("mock out the ListDeadLetters method")
+//			},
+//			PurgeDeadLettersFunc: func(ctx context.Context) (int, error) {
+//				panic("mock out the PurgeDeadLetters method")
+//			},
+//			RedeliverDeadLetterFunc: func(ctx context.Context, id string) (*model.DeliveryReport, error) {
+//				panic("mock out the RedeliverDeadLetter method")
+//			},
 //		}
 //
 //		// use mockedUseCases in code that requires interfaces.UseCases
@@ -31,11 +46,40 @@ var _ interfaces.UseCases = &UseCasesMock{}
 //
 //	}
 type UseCasesMock struct {
+	// DeleteDeadLetterFunc mocks the DeleteDeadLetter method.
+	DeleteDeadLetterFunc func(ctx context.Context, id string) error
+
+	// GetDeadLetterFunc mocks the GetDeadLetter method.
+	GetDeadLetterFunc func(ctx context.Context, id string) (*model.DeadLetter, error)
+
 	// HandleMessageFunc mocks the HandleMessage method.
-	HandleMessageFunc func(ctx context.Context, schema types.Schema, input *model.MessageQueryInput) error
+	HandleMessageFunc func(ctx context.Context, schema types.Schema, input *model.MessageQueryInput) (*model.DeliveryReport, error)
+
+	// ListDeadLettersFunc mocks the ListDeadLetters method.
+	ListDeadLettersFunc func(ctx context.Context) ([]*model.DeadLetter, error)
+
+	// PurgeDeadLettersFunc mocks the PurgeDeadLetters method.
+	PurgeDeadLettersFunc func(ctx context.Context) (int, error)
+
+	// RedeliverDeadLetterFunc mocks the RedeliverDeadLetter method.
+	RedeliverDeadLetterFunc func(ctx context.Context, id string) (*model.DeliveryReport, error)
 
 	// calls tracks calls to the methods.
 	calls struct {
+		// DeleteDeadLetter holds details about calls to the DeleteDeadLetter method.
+		DeleteDeadLetter []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+		}
+		// GetDeadLetter holds details about calls to the GetDeadLetter method.
+		GetDeadLetter []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+		}
 		// HandleMessage holds details about calls to the HandleMessage method.
 		HandleMessage []struct {
 			// Ctx is the ctx argument value.
@@ -45,12 +89,106 @@ type UseCasesMock struct {
 			// Input is the input argument value.
 			Input *model.MessageQueryInput
 		}
+		// ListDeadLetters holds details about calls to the ListDeadLetters method.
+		ListDeadLetters []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+		// PurgeDeadLetters holds details about calls to the PurgeDeadLetters method.
+		PurgeDeadLetters []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+		// RedeliverDeadLetter holds details about calls to the RedeliverDeadLetter method.
+		RedeliverDeadLetter []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+		}
+	}
+	lockDeleteDeadLetter    sync.RWMutex
+	lockGetDeadLetter       sync.RWMutex
+	lockHandleMessage       sync.RWMutex
+	lockListDeadLetters     sync.RWMutex
+	lockPurgeDeadLetters    sync.RWMutex
+	lockRedeliverDeadLetter sync.RWMutex
+}
+
+// DeleteDeadLetter calls DeleteDeadLetterFunc.
+func (mock *UseCasesMock) DeleteDeadLetter(ctx context.Context, id string) error {
+	if mock.DeleteDeadLetterFunc == nil {
+		panic("UseCasesMock.DeleteDeadLetterFunc: method is nil but UseCases.DeleteDeadLetter was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockDeleteDeadLetter.Lock()
+	mock.calls.DeleteDeadLetter = append(mock.calls.DeleteDeadLetter, callInfo)
+	mock.lockDeleteDeadLetter.Unlock()
+	return mock.DeleteDeadLetterFunc(ctx, id)
+}
+
+// DeleteDeadLetterCalls gets all the calls that were made to DeleteDeadLetter.
+// Check the length with:
+//
+//	len(mockedUseCases.DeleteDeadLetterCalls())
+func (mock *UseCasesMock) DeleteDeadLetterCalls() []struct {
+	Ctx context.Context
+	ID  string
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+	}
+	mock.lockDeleteDeadLetter.RLock()
+	calls = mock.calls.DeleteDeadLetter
+	mock.lockDeleteDeadLetter.RUnlock()
+	return calls
+}
+
+// GetDeadLetter calls GetDeadLetterFunc.
+func (mock *UseCasesMock) GetDeadLetter(ctx context.Context, id string) (*model.DeadLetter, error) {
+	if mock.GetDeadLetterFunc == nil {
+		panic("UseCasesMock.GetDeadLetterFunc: method is nil but UseCases.GetDeadLetter was just called")
 	}
-	lockHandleMessage sync.RWMutex
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockGetDeadLetter.Lock()
+	mock.calls.GetDeadLetter = append(mock.calls.GetDeadLetter, callInfo)
+	mock.lockGetDeadLetter.Unlock()
+	return mock.GetDeadLetterFunc(ctx, id)
+}
+
+// GetDeadLetterCalls gets all the calls that were made to GetDeadLetter.
+// Check the length with:
+//
+//	len(mockedUseCases.GetDeadLetterCalls())
+func (mock *UseCasesMock) GetDeadLetterCalls() []struct {
+	Ctx context.Context
+	ID  string
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+	}
+	mock.lockGetDeadLetter.RLock()
+	calls = mock.calls.GetDeadLetter
+	mock.lockGetDeadLetter.RUnlock()
+	return calls
 }
 
 // HandleMessage calls HandleMessageFunc.
-func (mock *UseCasesMock) HandleMessage(ctx context.Context, schema types.Schema, input *model.MessageQueryInput) error {
+func (mock *UseCasesMock) HandleMessage(ctx context.Context, schema types.Schema, input *model.MessageQueryInput) (*model.DeliveryReport, error) {
 	if mock.HandleMessageFunc == nil {
 		panic("UseCasesMock.HandleMessageFunc: method is nil but UseCases.HandleMessage was just called")
 	}
@@ -88,3 +226,103 @@ func (mock *UseCasesMock) HandleMessageCalls() []struct {
 	mock.lockHandleMessage.RUnlock()
 	return calls
 }
+
+// ListDeadLetters calls ListDeadLettersFunc.
+func (mock *UseCasesMock) ListDeadLetters(ctx context.Context) ([]*model.DeadLetter, error) {
+	if mock.ListDeadLettersFunc == nil {
+		panic("UseCasesMock.ListDeadLettersFunc: method is nil but UseCases.ListDeadLetters was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockListDeadLetters.Lock()
+	mock.calls.ListDeadLetters = append(mock.calls.ListDeadLetters, callInfo)
+	mock.lockListDeadLetters.Unlock()
+	return mock.ListDeadLettersFunc(ctx)
+}
+
+// ListDeadLettersCalls gets all the calls that were made to ListDeadLetters.
+// Check the length with:
+//
+//	len(mockedUseCases.ListDeadLettersCalls())
+func (mock *UseCasesMock) ListDeadLettersCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockListDeadLetters.RLock()
+	calls = mock.calls.ListDeadLetters
+	mock.lockListDeadLetters.RUnlock()
+	return calls
+}
+
+// PurgeDeadLetters calls PurgeDeadLettersFunc.
+func (mock *UseCasesMock) PurgeDeadLetters(ctx context.Context) (int, error) {
+	if mock.PurgeDeadLettersFunc == nil {
+		panic("UseCasesMock.PurgeDeadLettersFunc: method is nil but UseCases.PurgeDeadLetters was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockPurgeDeadLetters.Lock()
+	mock.calls.PurgeDeadLetters = append(mock.calls.PurgeDeadLetters, callInfo)
+	mock.lockPurgeDeadLetters.Unlock()
+	return mock.PurgeDeadLettersFunc(ctx)
+}
+
+// PurgeDeadLettersCalls gets all the calls that were made to PurgeDeadLetters.
+// Check the length with:
+//
+//	len(mockedUseCases.PurgeDeadLettersCalls())
+func (mock *UseCasesMock) PurgeDeadLettersCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockPurgeDeadLetters.RLock()
+	calls = mock.calls.PurgeDeadLetters
+	mock.lockPurgeDeadLetters.RUnlock()
+	return calls
+}
+
+// RedeliverDeadLetter calls RedeliverDeadLetterFunc.
+func (mock *UseCasesMock) RedeliverDeadLetter(ctx context.Context, id string) (*model.DeliveryReport, error) {
+	if mock.RedeliverDeadLetterFunc == nil {
+		panic("UseCasesMock.RedeliverDeadLetterFunc: method is nil but UseCases.RedeliverDeadLetter was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockRedeliverDeadLetter.Lock()
+	mock.calls.RedeliverDeadLetter = append(mock.calls.RedeliverDeadLetter, callInfo)
+	mock.lockRedeliverDeadLetter.Unlock()
+	return mock.RedeliverDeadLetterFunc(ctx, id)
+}
+
+// RedeliverDeadLetterCalls gets all the calls that were made to RedeliverDeadLetter.
+// Check the length with:
+//
+//	len(mockedUseCases.RedeliverDeadLetterCalls())
+func (mock *UseCasesMock) RedeliverDeadLetterCalls() []struct {
+	Ctx context.Context
+	ID  string
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+	}
+	mock.lockRedeliverDeadLetter.RLock()
+	calls = mock.calls.RedeliverDeadLetter
+	mock.lockRedeliverDeadLetter.RUnlock()
+	return calls
+}
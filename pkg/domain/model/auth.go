@@ -56,4 +56,6 @@ type AwsSNSAuth struct {
 	MessageId string `json:"MessageId"`
 	TopicArn  string `json:"TopicArn"`
 	Timestamp string `json:"Timestamp"`
+	// SubscriptionConfirmed is true if the subscription has been confirmed automatically
+	SubscriptionConfirmed bool `json:"SubscriptionConfirmed"`
 }
@@ -0,0 +1,68 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/m-mizutani/nounify/pkg/domain/types"
+)
+
+type DeliveryResult struct {
+	Destination types.Destination    `json:"destination"`
+	Workspace   string               `json:"workspace,omitempty"`
+	Channel     string               `json:"channel"`
+	Title       string               `json:"title,omitempty"`
+	Status      types.DeliveryStatus `json:"status"`
+	Error       string               `json:"error,omitempty"`
+}
+
+// DeliveryReport is a result of handling a webhook request. It is also returned to the sender as HTTP response body.
+type DeliveryReport struct {
+	// Duplicate is true if the request has been handled already and no message is delivered
+	Duplicate bool             `json:"duplicate,omitempty"`
+	Results   []DeliveryResult `json:"results"`
+}
+
+func (x *DeliveryReport) Count(status types.DeliveryStatus) int {
+	var n int
+	for _, r := range x.Results {
+		if r.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Delivery is a unit of message delivery. It keeps the original request to trace back where the message came from.
+type Delivery struct {
+	ID        string             `json:"id"`
+	Schema    types.Schema       `json:"schema"`
+	Input     *MessageQueryInput `json:"input"`
+	Message   Message            `json:"message"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+// NewDelivery creates a delivery of the message. Credentials in the input are redacted because the delivery is persisted in outbox and dead letter store.
+func NewDelivery(schema types.Schema, input *MessageQueryInput, msg Message) *Delivery {
+	return &Delivery{
+		ID:        uuid.NewString(),
+		Schema:    schema,
+		Input:     input.Redacted(),
+		Message:   msg,
+		CreatedAt: time.Now().UTC(),
+	}
+}
+
+// DeadLetter is a delivery that could not be delivered even after retries.
+type DeadLetter struct {
+	Delivery
+	Error    string    `json:"error"`
+	FailedAt time.Time `json:"failed_at"`
+}
+
+// MessageRef is a reference to a message posted to Slack.
+type MessageRef struct {
+	Channel   string    `json:"channel"`
+	Timestamp string    `json:"timestamp"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -2,16 +2,40 @@ package model
 
 import "time"
 
+// PubSubEnvelope is a body of Google Pub/Sub push request
+type PubSubEnvelope struct {
+	Message      *PubSubMessage `json:"message"`
+	Subscription string         `json:"subscription"`
+}
+
 type PubSubMessage struct {
 	Data        []byte            `json:"data"`
 	Attributes  map[string]string `json:"attributes"`
-	MessageID   string            `json:"message_id"`
-	PublishTime string            `json:"publish_time"`
+	MessageID   string            `json:"messageId"`
+	PublishTime string            `json:"publishTime"`
 }
 
+// InputPubSub is a decoded Pub/Sub push request. Data is parsed as JSON if possible, otherwise it is a string.
 type InputPubSub struct {
-	Data        any               `json:"data"`
-	Attributes  map[string]string `json:"attributes"`
-	MessageID   string            `json:"message_id"`
-	PublishTime time.Time         `json:"publish_time"`
+	Data         any               `json:"data"`
+	Attributes   map[string]string `json:"attributes"`
+	MessageID    string            `json:"message_id"`
+	Subscription string            `json:"subscription"`
+	PublishTime  time.Time         `json:"publish_time"`
+}
+
+// InputSNS is a decoded message of Amazon SNS. Message is parsed as JSON if possible, otherwise it is a string.
+type InputSNS struct {
+	Type              string                         `json:"Type"`
+	MessageId         string                         `json:"MessageId"`
+	TopicArn          string                         `json:"TopicArn"`
+	Subject           string                         `json:"Subject"`
+	Message           any                            `json:"Message"`
+	Timestamp         string                         `json:"Timestamp"`
+	MessageAttributes map[string]SNSMessageAttribute `json:"MessageAttributes"`
+}
+
+type SNSMessageAttribute struct {
+	Type  string `json:"Type"`
+	Value string `json:"Value"`
 }
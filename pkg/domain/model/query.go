@@ -1,18 +1,75 @@
 package model
 
+import (
+	"net/http"
+	"slices"
+
+	"github.com/m-mizutani/nounify/pkg/domain/types"
+)
+
 type MessageQueryInput struct {
 	Method string            `json:"method"`
 	Path   string            `json:"path"`
 	Header map[string]string `json:"header"`
 	Body   any               `json:"body"`
 	Auth   AuthContext       `json:"auth"`
+	// PubSub is set if the request is a push request of Google Pub/Sub
+	PubSub *InputPubSub `json:"pubsub,omitempty"`
+	// SNS is set if the request is a message of Amazon SNS
+	SNS *InputSNS `json:"sns,omitempty"`
+}
+
+// redactedHeaders are request headers having credentials, e.g. tokens and signatures
+var redactedHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"X-Hub-Signature",
+	"X-Hub-Signature-256",
+}
+
+// Redacted returns a copy of the input with values of credential headers replaced
+func (x *MessageQueryInput) Redacted() *MessageQueryInput {
+	if x == nil {
+		return nil
+	}
+
+	redacted := *x
+	if x.Header == nil {
+		return &redacted
+	}
+
+	redacted.Header = make(map[string]string, len(x.Header))
+	for key, value := range x.Header {
+		if slices.Contains(redactedHeaders, http.CanonicalHeaderKey(key)) {
+			value = "[REDACTED]"
+		}
+		redacted.Header[key] = value
+	}
+	return &redacted
 }
 
 type MessageQueryOutput struct {
 	Messages []Message `json:"msg"`
+	// DedupKey is a key to detect duplicated requests. It is prioritized over delivery ID of the provider.
+	DedupKey string `json:"dedup_key"`
+	// Digest is default digest setting for messages with group in the schema
+	Digest *DigestConfig `json:"digest"`
+}
+
+type DigestConfig struct {
+	// Window is a duration to buffer messages, e.g. "5m"
+	Window string `json:"window"`
+	// Max is the maximum number of messages in a digest. The digest is posted immediately when it is reached.
+	Max int `json:"max"`
 }
 
 type Message struct {
+	// Destination is a service to deliver the message. Default is slack.
+	Destination types.Destination `json:"destination"`
+	// Workspace is a name of Slack workspace configured at startup. The default workspace is used if not specified.
+	Workspace string `json:"workspace"`
+
 	Channel string         `json:"channel"`
 	Color   string         `json:"color"`
 	Title   string         `json:"title"`
@@ -20,6 +77,83 @@ type Message struct {
 	Fields  []MessageField `json:"fields"`
 	Icon    string         `json:"icon"`
 	Emoji   string         `json:"emoji"`
+
+	// Blocks is raw Block Kit blocks of Slack. It is posted instead of title, body and fields if specified.
+	Blocks []map[string]any `json:"blocks,omitempty"`
+
+	// Files are uploaded to the same channel or thread as the message
+	Files []MessageFile `json:"files,omitempty"`
+
+	// DedupKey and SuppressFor suppress messages with the same key for the duration (e.g. "30m")
+	DedupKey    string `json:"dedup_key"`
+	SuppressFor string `json:"suppress_for"`
+
+	// Group buffers messages with the same group and channel, and posts them as one digest message
+	Group        string `json:"group"`
+	DigestWindow string `json:"digest_window"`
+	DigestMax    int    `json:"digest_max"`
+
+	// ThreadKey posts the message as a reply to the first message with the same key in the channel
+	ThreadKey string `json:"thread_key"`
+
+	// UpdateKey replaces the previously posted message with the same key in the channel instead of posting a new one
+	UpdateKey string `json:"update_key"`
+
+	// Webhook is a HTTP request for webhook destination
+	Webhook *WebhookRequest `json:"webhook,omitempty"`
+
+	// PagerDuty is an event option for pagerduty destination
+	PagerDuty *PagerDutyEvent `json:"pagerduty,omitempty"`
+}
+
+type WebhookRequest struct {
+	URL    string            `json:"url"`
+	Method string            `json:"method"`
+	Header map[string]string `json:"header"`
+	// Body is sent as it is if string, otherwise encoded as JSON. The message is sent as JSON if not specified.
+	Body any `json:"body"`
+}
+
+// DestinationOrDefault returns the destination of the message. It returns slack if not specified.
+func (x Message) DestinationOrDefault() types.Destination {
+	if x.Destination == "" {
+		return types.DestinationSlack
+	}
+	return x.Destination
+}
+
+const (
+	ColorInfo    = "info"
+	ColorWarning = "warning"
+	ColorError   = "error"
+)
+
+var preservedColors = map[string]string{
+	ColorInfo:    "#2EB67D",
+	ColorWarning: "#FFA500",
+	ColorError:   "#FF0000",
+}
+
+// ColorCode returns the color of the message as hex code. Preserved color names are converted, and info is used if the color is not specified.
+func (x Message) ColorCode() string {
+	if x.Color == "" {
+		return preservedColors[ColorInfo]
+	}
+	if preserved, ok := preservedColors[x.Color]; ok {
+		return preserved
+	}
+	return x.Color
+}
+
+type PagerDutyEvent struct {
+	// EventAction is one of trigger (default), acknowledge and resolve
+	EventAction string `json:"event_action"`
+	// Severity is one of critical, error, warning and info. It is derived from color if not specified.
+	Severity  string `json:"severity"`
+	Source    string `json:"source"`
+	Component string `json:"component"`
+	Group     string `json:"group"`
+	Class     string `json:"class"`
 }
 
 type MessageField struct {
@@ -28,6 +162,13 @@ type MessageField struct {
 	Link  string `json:"link"`
 }
 
+// MessageFile is a text file uploaded with the message, e.g. stack trace or diff output
+type MessageFile struct {
+	Name    string `json:"name"`
+	Title   string `json:"title"`
+	Content string `json:"content"`
+}
+
 type AuthContext struct {
 	GitHub GitHubAuth     `json:"github"`
 	Google map[string]any `json:"google"`
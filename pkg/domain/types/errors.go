@@ -22,8 +22,14 @@ func (x Error) Wrap(cause error) Error {
 func (x Error) Unwrap() error { return x.cause }
 
 var (
-	ErrInvalidContentType = Error{code: http.StatusBadRequest, msg: "unsupported Content-Type"}
-	ErrInvalidInput       = Error{code: http.StatusBadRequest, msg: "invalid input"}
-	ErrAuthFailed         = Error{code: http.StatusUnauthorized, msg: "authentication failed"}
-	ErrForbidden          = Error{code: http.StatusForbidden, msg: "forbidden"}
+	ErrInvalidContentType  = Error{code: http.StatusBadRequest, msg: "unsupported Content-Type"}
+	ErrInvalidInput        = Error{code: http.StatusBadRequest, msg: "invalid input"}
+	ErrInvalidPolicyOutput = Error{code: http.StatusInternalServerError, msg: "invalid policy output"}
+	ErrAuthFailed          = Error{code: http.StatusUnauthorized, msg: "authentication failed"}
+	ErrForbidden           = Error{code: http.StatusForbidden, msg: "forbidden"}
+	ErrNotFound            = Error{code: http.StatusNotFound, msg: "not found"}
+	ErrDeliveryFailed      = Error{code: http.StatusInternalServerError, msg: "failed to deliver message"}
+	ErrQueueFull           = Error{code: http.StatusServiceUnavailable, msg: "delivery queue is full"}
+	ErrQueueClosed         = Error{code: http.StatusServiceUnavailable, msg: "delivery queue is closed"}
+	ErrDeadLetterDisabled  = Error{code: http.StatusNotFound, msg: "dead letter store is not enabled"}
 )
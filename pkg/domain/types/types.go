@@ -5,3 +5,27 @@ type Schema string
 func (x Schema) ToQuery() string {
 	return "data.msg." + string(x)
 }
+
+type DeliveryStatus string
+
+const (
+	DeliverySucceeded  DeliveryStatus = "succeeded"
+	DeliveryFailed     DeliveryStatus = "failed"
+	DeliveryQueued     DeliveryStatus = "queued"
+	DeliverySuppressed DeliveryStatus = "suppressed"
+	DeliveryBuffered   DeliveryStatus = "buffered"
+)
+
+// Destination is a kind of service to deliver a message.
+type Destination string
+
+const (
+	DestinationSlack      Destination = "slack"
+	DestinationTeams      Destination = "teams"
+	DestinationDiscord    Destination = "discord"
+	DestinationWebhook    Destination = "webhook"
+	DestinationEmail      Destination = "email"
+	DestinationPagerDuty  Destination = "pagerduty"
+	DestinationMattermost Destination = "mattermost"
+	DestinationRocketChat Destination = "rocketchat"
+)
@@ -0,0 +1,163 @@
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/nounify/pkg/domain/interfaces"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+	"github.com/m-mizutani/nounify/pkg/domain/types"
+	"github.com/m-mizutani/nounify/pkg/infra/httpclient"
+	"github.com/m-mizutani/nounify/pkg/infra/retry"
+)
+
+// Limits of embed. Discord rejects a message exceeding them.
+const (
+	maxTitleLength       = 256
+	maxDescriptionLength = 4096
+	maxFields            = 25
+	maxFieldNameLength   = 256
+	maxFieldValueLength  = 1024
+)
+
+// Notifier posts messages as an embed to Discord webhooks. Channel of the message is a name of the webhook.
+type Notifier struct {
+	webhooks map[string]string
+	client   *http.Client
+}
+
+var _ interfaces.Notifier = &Notifier{}
+
+type Option func(*Notifier)
+
+func WithHTTPClient(client *http.Client) Option {
+	return func(x *Notifier) {
+		x.client = client
+	}
+}
+
+// New creates a Discord notifier. webhooks is a map of channel name to webhook URL.
+func New(webhooks map[string]string, options ...Option) *Notifier {
+	x := &Notifier{
+		webhooks: webhooks,
+		client:   httpclient.New(),
+	}
+	for _, option := range options {
+		option(x)
+	}
+	return x
+}
+
+func (x *Notifier) Notify(ctx context.Context, msg model.Message) error {
+	url, ok := x.webhooks[msg.Channel]
+	if !ok {
+		return goerr.Wrap(types.ErrInvalidPolicyOutput, "Discord webhook is not configured for the channel").With("channel", msg.Channel)
+	}
+
+	if _, err := httpclient.PostJSON(ctx, x.client, url, buildPayload(msg)); err != nil {
+		return goerr.Wrap(handleRateLimit(err), "failed to post message to Discord").With("channel", msg.Channel)
+	}
+
+	return nil
+}
+
+// handleRateLimit uses retry_after in the response body of 429 if Retry-After header is not available
+func handleRateLimit(err error) error {
+	var statusErr *httpclient.StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusTooManyRequests {
+		return err
+	}
+	if statusErr.Header.Get("Retry-After") != "" {
+		return err
+	}
+
+	var resp struct {
+		RetryAfter float64 `json:"retry_after"`
+		Global     bool    `json:"global"`
+	}
+	if json.Unmarshal(statusErr.Body, &resp) != nil || resp.RetryAfter <= 0 {
+		return err
+	}
+
+	return retry.Temporary(err, time.Duration(resp.RetryAfter*float64(time.Second)))
+}
+
+type payload struct {
+	AvatarURL string  `json:"avatar_url,omitempty"`
+	Embeds    []embed `json:"embeds"`
+}
+
+type embed struct {
+	Title       string       `json:"title,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Color       int          `json:"color,omitempty"`
+	Fields      []embedField `json:"fields,omitempty"`
+}
+
+type embedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+func buildPayload(msg model.Message) payload {
+	e := embed{
+		Title:       truncate(msg.Title, maxTitleLength),
+		Description: truncate(msg.Body, maxDescriptionLength),
+		Color:       colorToInt(msg.ColorCode()),
+	}
+
+	for i, field := range msg.Fields {
+		if i >= maxFields {
+			break
+		}
+
+		value := field.Value
+		if field.Link != "" {
+			value = "[" + field.Value + "](" + field.Link + ")"
+		}
+		// Discord rejects a field with empty name or value, then zero width space is used instead
+		name := field.Name
+		if name == "" {
+			name = "\u200b"
+		}
+		if value == "" {
+			value = "\u200b"
+		}
+
+		e.Fields = append(e.Fields, embedField{
+			Name:   truncate(name, maxFieldNameLength),
+			Value:  truncate(value, maxFieldValueLength),
+			Inline: true,
+		})
+	}
+
+	return payload{
+		AvatarURL: msg.Icon,
+		Embeds:    []embed{e},
+	}
+}
+
+// colorToInt converts a color code such as "#2EB67D" to an integer. It returns 0 (no color) if the code is invalid.
+func colorToInt(code string) int {
+	v, err := strconv.ParseUint(strings.TrimPrefix(code, "#"), 16, 32)
+	if err != nil || v > 0xFFFFFF {
+		return 0
+	}
+	return int(v)
+}
+
+func truncate(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:max-1]) + "…"
+}
@@ -0,0 +1,96 @@
+package discord_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+	"github.com/m-mizutani/nounify/pkg/infra/discord"
+	"github.com/m-mizutani/nounify/pkg/infra/retry"
+)
+
+func TestNotify(t *testing.T) {
+	var received map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gt.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	notifier := discord.New(map[string]string{"community": srv.URL})
+	gt.NoError(t, notifier.Notify(context.Background(), model.Message{
+		Channel: "community",
+		Color:   "warning",
+		Title:   "New release",
+		Body:    strings.Repeat("a", 5000),
+		Icon:    "https://example.com/icon.png",
+		Fields: []model.MessageField{
+			{Name: "Version", Value: "v1.0.0", Link: "https://example.com/releases/v1.0.0"},
+			{Name: "Note", Value: ""},
+		},
+	}))
+
+	gt.Equal(t, received["avatar_url"], "https://example.com/icon.png")
+	e := received["embeds"].([]any)[0].(map[string]any)
+	gt.Equal(t, e["title"], "New release")
+	gt.Equal(t, e["color"].(float64), 0xFFA500)
+	gt.Equal(t, len([]rune(e["description"].(string))), 4096)
+
+	fields := e["fields"].([]any)
+	gt.A(t, fields).Length(2)
+	gt.Equal(t, fields[0].(map[string]any)["value"], "[v1.0.0](https://example.com/releases/v1.0.0)")
+	gt.Equal(t, fields[0].(map[string]any)["inline"], true)
+	gt.Equal(t, fields[1].(map[string]any)["value"], "\u200b")
+}
+
+func TestNotifyRateLimited(t *testing.T) {
+	t.Run("retry_after in body", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"message": "You are being rate limited.", "retry_after": 1.5, "global": false}`))
+		}))
+		defer srv.Close()
+
+		notifier := discord.New(map[string]string{"community": srv.URL})
+		err := notifier.Notify(context.Background(), model.Message{Channel: "community"})
+		gt.Error(t, err)
+
+		retryable, wait := retry.IsTemporary(err)
+		gt.True(t, retryable)
+		gt.Equal(t, wait, 1500*time.Millisecond)
+	})
+
+	t.Run("Retry-After header", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"retry_after": 1.5}`))
+		}))
+		defer srv.Close()
+
+		notifier := discord.New(map[string]string{"community": srv.URL})
+		err := notifier.Notify(context.Background(), model.Message{Channel: "community"})
+		retryable, wait := retry.IsTemporary(err)
+		gt.True(t, retryable)
+		gt.Equal(t, wait, 2*time.Second)
+	})
+
+	t.Run("bad request is not retried", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer srv.Close()
+
+		notifier := discord.New(map[string]string{"community": srv.URL})
+		err := notifier.Notify(context.Background(), model.Message{Channel: "community"})
+		gt.Error(t, err)
+		retryable, _ := retry.IsTemporary(err)
+		gt.False(t, retryable)
+	})
+}
@@ -0,0 +1,336 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/nounify/pkg/domain/interfaces"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+	"github.com/m-mizutani/nounify/pkg/domain/types"
+	"github.com/m-mizutani/nounify/pkg/infra/retry"
+)
+
+const defaultTimeout = 30 * time.Second
+
+// Notifier sends messages as email by SMTP. Channel of the message is a comma separated list of recipient addresses.
+type Notifier struct {
+	host     string
+	port     int
+	from     string
+	username string
+	password string
+	startTLS bool
+	timeout  time.Duration
+	now      func() time.Time
+}
+
+var _ interfaces.Notifier = &Notifier{}
+
+type Option func(*Notifier)
+
+// WithAuth enables SMTP authentication with PLAIN mechanism. The connection must be encrypted by STARTTLS except localhost.
+func WithAuth(username, password string) Option {
+	return func(x *Notifier) {
+		x.username = username
+		x.password = password
+	}
+}
+
+// WithStartTLS requires upgrading the connection by STARTTLS before sending a message.
+func WithStartTLS() Option {
+	return func(x *Notifier) {
+		x.startTLS = true
+	}
+}
+
+// WithTimeout sets timeout of whole SMTP session to send a message.
+func WithTimeout(timeout time.Duration) Option {
+	return func(x *Notifier) {
+		x.timeout = timeout
+	}
+}
+
+func New(host string, port int, from string, options ...Option) *Notifier {
+	x := &Notifier{
+		host:    host,
+		port:    port,
+		from:    from,
+		timeout: defaultTimeout,
+		now:     time.Now,
+	}
+	for _, option := range options {
+		option(x)
+	}
+	return x
+}
+
+func (x *Notifier) Notify(ctx context.Context, msg model.Message) error {
+	from, err := mail.ParseAddress(x.from)
+	if err != nil {
+		return goerr.Wrap(err, "invalid sender address").With("from", x.from)
+	}
+	to, err := mail.ParseAddressList(msg.Channel)
+	if err != nil {
+		return goerr.Wrap(types.ErrInvalidPolicyOutput.Wrap(err), "channel must be recipient email addresses").With("channel", msg.Channel)
+	}
+
+	data, err := buildMail(from, to, msg, x.now())
+	if err != nil {
+		return err
+	}
+
+	if err := x.send(ctx, from, to, data); err != nil {
+		return handleSMTPError(goerr.Wrap(err, "failed to send email").With("host", x.host).With("channel", msg.Channel))
+	}
+
+	return nil
+}
+
+func (x *Notifier) send(ctx context.Context, from *mail.Address, to []*mail.Address, data []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, x.timeout)
+	defer cancel()
+
+	addr := net.JoinHostPort(x.host, strconv.Itoa(x.port))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	// net/smtp does not support context, then the deadline is applied to the connection
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			conn.Close()
+			return err
+		}
+	}
+
+	client, err := smtp.NewClient(conn, x.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if x.startTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return goerr.New("SMTP server does not support STARTTLS")
+		}
+		if err := client.StartTLS(&tls.Config{ServerName: x.host, MinVersion: tls.VersionTLS12}); err != nil {
+			return err
+		}
+	}
+
+	if x.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", x.username, x.password, x.host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(from.Address); err != nil {
+		return err
+	}
+	for _, rcpt := range to {
+		if err := client.Rcpt(rcpt.Address); err != nil {
+			return goerr.Wrap(err).With("rcpt", rcpt.Address)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// handleSMTPError marks transient failures, i.e. 4xx reply and network errors, as temporary
+func handleSMTPError(err error) error {
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		if 400 <= protoErr.Code && protoErr.Code < 500 {
+			return retry.Temporary(err, 0)
+		}
+		return err
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return retry.Temporary(err, 0)
+	}
+
+	return err
+}
+
+var htmlTemplate = template.Must(template.New("mail").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif;">
+<div style="border-left: 4px solid {{.Color}}; padding-left: 12px;">
+{{- if .Title}}
+<h2 style="margin: 0 0 8px 0;">{{.Title}}</h2>
+{{- end}}
+{{- if .Body}}
+<p style="white-space: pre-wrap; margin: 0 0 8px 0;">{{.Body}}</p>
+{{- end}}
+{{- if .Fields}}
+<table style="border-collapse: collapse;">
+{{- range .Fields}}
+<tr>
+<th style="text-align: left; padding: 4px 12px 4px 0; vertical-align: top;">{{.Name}}</th>
+<td style="padding: 4px 0;">{{if .Link}}<a href="{{.Link}}">{{.Value}}</a>{{else}}{{.Value}}{{end}}</td>
+</tr>
+{{- end}}
+</table>
+{{- end}}
+</div>
+</body>
+</html>
+`))
+
+func buildText(msg model.Message) string {
+	var b strings.Builder
+	if msg.Title != "" {
+		b.WriteString(msg.Title + "\n\n")
+	}
+	if msg.Body != "" {
+		b.WriteString(msg.Body + "\n\n")
+	}
+	for _, field := range msg.Fields {
+		b.WriteString(field.Name + ": " + field.Value)
+		if field.Link != "" {
+			b.WriteString(" <" + field.Link + ">")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func buildHTML(msg model.Message) (string, error) {
+	var b bytes.Buffer
+	err := htmlTemplate.Execute(&b, struct {
+		model.Message
+		Color template.CSS
+	}{
+		Message: msg,
+		Color:   template.CSS(safeColor(msg.ColorCode())),
+	})
+	if err != nil {
+		return "", goerr.Wrap(err, "failed to render HTML mail")
+	}
+	return b.String(), nil
+}
+
+// safeColor allows only hex color code to be embedded into CSS
+func safeColor(code string) string {
+	const fallback = "#2EB67D"
+
+	hexCode := strings.TrimPrefix(code, "#")
+	if len(hexCode) != 3 && len(hexCode) != 6 {
+		return fallback
+	}
+	for _, c := range hexCode {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+			return fallback
+		}
+	}
+	return "#" + hexCode
+}
+
+// buildMail renders the message as multipart/alternative email with text and HTML parts
+func buildMail(from *mail.Address, to []*mail.Address, msg model.Message, now time.Time) ([]byte, error) {
+	htmlBody, err := buildHTML(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	subject := msg.Title
+	if subject == "" {
+		subject = "Notification from nounify"
+	}
+
+	recipients := make([]string, len(to))
+	for i, addr := range to {
+		recipients[i] = addr.String()
+	}
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+
+	header := []string{
+		"From: " + from.String(),
+		"To: " + strings.Join(recipients, ", "),
+		"Subject: " + mime.QEncoding.Encode("utf-8", subject),
+		"Date: " + now.Format(time.RFC1123Z),
+		"Message-ID: " + messageID(from),
+		"MIME-Version: 1.0",
+		"Content-Type: multipart/alternative; boundary=" + mw.Boundary(),
+	}
+	var data bytes.Buffer
+	data.WriteString(strings.Join(header, "\r\n") + "\r\n\r\n")
+
+	for _, part := range []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain; charset=utf-8", buildText(msg)},
+		{"text/html; charset=utf-8", htmlBody},
+	} {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to create mail part")
+		}
+		qw := quotedprintable.NewWriter(pw)
+		if _, err := qw.Write([]byte(part.body)); err != nil {
+			return nil, goerr.Wrap(err, "failed to write mail part")
+		}
+		if err := qw.Close(); err != nil {
+			return nil, goerr.Wrap(err, "failed to write mail part")
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, goerr.Wrap(err, "failed to close multipart mail")
+	}
+
+	data.Write(buf.Bytes())
+	return data.Bytes(), nil
+}
+
+func messageID(from *mail.Address) string {
+	domain := "nounify"
+	if _, d, ok := strings.Cut(from.Address, "@"); ok {
+		domain = d
+	}
+
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(b[:]), domain)
+}
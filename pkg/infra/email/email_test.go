@@ -0,0 +1,188 @@
+package email_test
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+	"github.com/m-mizutani/nounify/pkg/infra/email"
+	"github.com/m-mizutani/nounify/pkg/infra/retry"
+)
+
+// fakeSMTP is a minimal SMTP server to receive a message
+type fakeSMTP struct {
+	listener net.Listener
+	rcptCode string
+
+	mutex sync.Mutex
+	auth  string
+	from  string
+	rcpts []string
+	data  string
+}
+
+func newFakeSMTP(t *testing.T) *fakeSMTP {
+	listener := gt.R1(net.Listen("tcp", "127.0.0.1:0")).NoError(t)
+	srv := &fakeSMTP{listener: listener, rcptCode: "250 OK"}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go srv.serve(conn)
+		}
+	}()
+
+	return srv
+}
+
+func (x *fakeSMTP) addr() (string, int) {
+	addr := x.listener.Addr().(*net.TCPAddr)
+	return "127.0.0.1", addr.Port
+}
+
+func (x *fakeSMTP) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(s string) { _, _ = io.WriteString(conn, s+"\r\n") }
+
+	reply("220 localhost ESMTP fake")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		x.mutex.Lock()
+		switch cmd {
+		case "EHLO":
+			reply("250-localhost")
+			reply("250 AUTH PLAIN")
+		case "AUTH":
+			x.auth = line
+			reply("235 Authentication successful")
+		case "MAIL":
+			x.from = line
+			reply("250 OK")
+		case "RCPT":
+			x.rcpts = append(x.rcpts, line)
+			reply(x.rcptCode)
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil || l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			x.data = data.String()
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			x.mutex.Unlock()
+			return
+		default:
+			reply("250 OK")
+		}
+		x.mutex.Unlock()
+	}
+}
+
+func TestNotify(t *testing.T) {
+	srv := newFakeSMTP(t)
+	host, port := srv.addr()
+
+	notifier := email.New(host, port, "nounify <noreply@example.com>",
+		email.WithAuth("user", "pass"),
+		email.WithTimeout(time.Second),
+	)
+	gt.NoError(t, notifier.Notify(context.Background(), model.Message{
+		Channel: "security@example.com, Compliance <compliance@example.com>",
+		Color:   "warning",
+		Title:   "Quarterly access review",
+		Body:    "Please review <access> list",
+		Fields: []model.MessageField{
+			{Name: "Due", Value: "2024-04-01"},
+			{Name: "Sheet", Value: "link", Link: "https://example.com/sheet"},
+		},
+	}))
+
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	gt.S(t, srv.auth).HasPrefix("AUTH PLAIN")
+	gt.Equal(t, srv.from, "MAIL FROM:<noreply@example.com>")
+	gt.Equal(t, srv.rcpts, []string{"RCPT TO:<security@example.com>", "RCPT TO:<compliance@example.com>"})
+
+	msg := gt.R1(mail.ReadMessage(strings.NewReader(srv.data))).NoError(t)
+	gt.Equal(t, msg.Header.Get("Subject"), "Quarterly access review")
+	gt.S(t, msg.Header.Get("To")).Contains("compliance@example.com")
+
+	mediaType, params := gt.R2(mime.ParseMediaType(msg.Header.Get("Content-Type"))).NoError(t)
+	gt.Equal(t, mediaType, "multipart/alternative")
+
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	parts := map[string]string{}
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		gt.NoError(t, err)
+		body := gt.R1(io.ReadAll(part)).NoError(t)
+		parts[strings.Split(part.Header.Get("Content-Type"), ";")[0]] = string(body)
+	}
+
+	gt.S(t, parts["text/plain"]).Contains("Due: 2024-04-01")
+	gt.S(t, parts["text/plain"]).Contains("Sheet: link <https://example.com/sheet>")
+	gt.S(t, parts["text/html"]).Contains("border-left: 4px solid #FFA500")
+	gt.S(t, parts["text/html"]).Contains("Please review &lt;access&gt; list")
+	gt.S(t, parts["text/html"]).Contains(`<a href="https://example.com/sheet">link</a>`)
+}
+
+func TestNotifyTemporaryFailure(t *testing.T) {
+	srv := newFakeSMTP(t)
+	srv.rcptCode = "451 Try again later"
+	host, port := srv.addr()
+
+	notifier := email.New(host, port, "noreply@example.com")
+	err := notifier.Notify(context.Background(), model.Message{Channel: "ops@example.com"})
+	gt.Error(t, err)
+
+	retryable, _ := retry.IsTemporary(err)
+	gt.True(t, retryable)
+}
+
+func TestNotifyInvalidRecipient(t *testing.T) {
+	notifier := email.New("127.0.0.1", 25, "noreply@example.com")
+	err := notifier.Notify(context.Background(), model.Message{Channel: "ops"})
+	gt.Error(t, err)
+
+	retryable, _ := retry.IsTemporary(err)
+	gt.False(t, retryable)
+}
+
+func TestNotifyStartTLSRequired(t *testing.T) {
+	srv := newFakeSMTP(t)
+	host, port := srv.addr()
+
+	notifier := email.New(host, port, "noreply@example.com", email.WithStartTLS())
+	gt.Error(t, notifier.Notify(context.Background(), model.Message{Channel: "ops@example.com"}))
+}
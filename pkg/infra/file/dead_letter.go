@@ -0,0 +1,61 @@
+package file
+
+import (
+	"context"
+	"sort"
+
+	"github.com/m-mizutani/nounify/pkg/domain/interfaces"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+)
+
+// DeadLetterStore is a file based implementation of interfaces.DeadLetterStore.
+type DeadLetterStore struct {
+	store *jsonDir[model.DeadLetter]
+}
+
+var _ interfaces.DeadLetterStore = &DeadLetterStore{}
+
+func NewDeadLetterStore(dir string) (*DeadLetterStore, error) {
+	store, err := newJSONDir[model.DeadLetter](dir)
+	if err != nil {
+		return nil, err
+	}
+	return &DeadLetterStore{store: store}, nil
+}
+
+func (x *DeadLetterStore) Put(ctx context.Context, dl *model.DeadLetter) error {
+	return x.store.put(dl.ID, dl)
+}
+
+func (x *DeadLetterStore) Get(ctx context.Context, id string) (*model.DeadLetter, error) {
+	return x.store.get(id)
+}
+
+// List returns dead letters in order of failure
+func (x *DeadLetterStore) List(ctx context.Context) ([]*model.DeadLetter, error) {
+	keys, err := x.store.keys()
+	if err != nil {
+		return nil, err
+	}
+
+	var deadLetters []*model.DeadLetter
+	for _, key := range keys {
+		dl, err := x.store.get(key)
+		if err != nil {
+			return nil, err
+		}
+		if dl != nil {
+			deadLetters = append(deadLetters, dl)
+		}
+	}
+
+	sort.SliceStable(deadLetters, func(i, j int) bool {
+		return deadLetters[i].FailedAt.Before(deadLetters[j].FailedAt)
+	})
+
+	return deadLetters, nil
+}
+
+func (x *DeadLetterStore) Delete(ctx context.Context, id string) error {
+	return x.store.delete(id)
+}
@@ -0,0 +1,39 @@
+package file
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/m-mizutani/nounify/pkg/domain/interfaces"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+)
+
+// MessageRefStore is a file based implementation of interfaces.MessageRefStore.
+type MessageRefStore struct {
+	store *jsonDir[model.MessageRef]
+}
+
+var _ interfaces.MessageRefStore = &MessageRefStore{}
+
+func NewMessageRefStore(dir string) (*MessageRefStore, error) {
+	store, err := newJSONDir[model.MessageRef](dir)
+	if err != nil {
+		return nil, err
+	}
+	return &MessageRefStore{store: store}, nil
+}
+
+// refFileKey converts arbitrary key from policy to a safe file name
+func refFileKey(key string) string {
+	h := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(h[:])
+}
+
+func (x *MessageRefStore) Get(ctx context.Context, key string) (*model.MessageRef, error) {
+	return x.store.get(refFileKey(key))
+}
+
+func (x *MessageRefStore) Put(ctx context.Context, key string, ref *model.MessageRef) error {
+	return x.store.put(refFileKey(key), ref)
+}
@@ -0,0 +1,57 @@
+package file
+
+import (
+	"context"
+	"sort"
+
+	"github.com/m-mizutani/nounify/pkg/domain/interfaces"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+)
+
+// Outbox is a file based implementation of interfaces.Outbox. Each delivery is stored as a JSON file in the directory.
+type Outbox struct {
+	store *jsonDir[model.Delivery]
+}
+
+var _ interfaces.Outbox = &Outbox{}
+
+func NewOutbox(dir string) (*Outbox, error) {
+	store, err := newJSONDir[model.Delivery](dir)
+	if err != nil {
+		return nil, err
+	}
+	return &Outbox{store: store}, nil
+}
+
+func (x *Outbox) Put(ctx context.Context, d *model.Delivery) error {
+	return x.store.put(d.ID, d)
+}
+
+func (x *Outbox) Delete(ctx context.Context, id string) error {
+	return x.store.delete(id)
+}
+
+// List returns pending deliveries in order of creation
+func (x *Outbox) List(ctx context.Context) ([]*model.Delivery, error) {
+	keys, err := x.store.keys()
+	if err != nil {
+		return nil, err
+	}
+
+	var deliveries []*model.Delivery
+	for _, key := range keys {
+		d, err := x.store.get(key)
+		if err != nil {
+			return nil, err
+		}
+		if d != nil {
+			deliveries = append(deliveries, d)
+		}
+	}
+
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt.Before(deliveries[j].CreatedAt)
+	})
+
+	return deliveries, nil
+}
@@ -0,0 +1,44 @@
+package file_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+	"github.com/m-mizutani/nounify/pkg/infra/file"
+)
+
+func TestOutbox(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	outbox := gt.R1(file.NewOutbox(dir)).NoError(t)
+
+	d1 := model.NewDelivery("test", &model.MessageQueryInput{Method: "POST"}, model.Message{Channel: "ch1"})
+	d2 := model.NewDelivery("test", &model.MessageQueryInput{Method: "POST"}, model.Message{Channel: "ch2"})
+	d2.CreatedAt = d1.CreatedAt.Add(time.Second)
+
+	gt.NoError(t, outbox.Put(ctx, d2))
+	gt.NoError(t, outbox.Put(ctx, d1))
+
+	// Reopen to confirm entries are persisted
+	outbox = gt.R1(file.NewOutbox(dir)).NoError(t)
+	list := gt.R1(outbox.List(ctx)).NoError(t)
+	gt.A(t, list).Length(2)
+	gt.Equal(t, list[0].ID, d1.ID)
+	gt.Equal(t, list[0].Message.Channel, "ch1")
+	gt.Equal(t, list[0].Input.Method, "POST")
+	gt.Equal(t, list[1].ID, d2.ID)
+
+	gt.NoError(t, outbox.Delete(ctx, d1.ID))
+	gt.NoError(t, outbox.Delete(ctx, d1.ID)) // deleting twice is not error
+	list = gt.R1(outbox.List(ctx)).NoError(t)
+	gt.A(t, list).Length(1)
+	gt.Equal(t, list[0].ID, d2.ID)
+
+	t.Run("invalid ID", func(t *testing.T) {
+		gt.Error(t, outbox.Delete(ctx, "../outside"))
+		gt.Error(t, outbox.Put(ctx, &model.Delivery{ID: "a/b"}))
+	})
+}
@@ -0,0 +1,136 @@
+package file
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/m-mizutani/goerr"
+)
+
+const fileExt = ".json"
+
+var validKey = regexp.MustCompile(`^[0-9A-Za-z][0-9A-Za-z._-]*$`)
+
+// jsonDir stores records as JSON files, one file per record, in a directory.
+type jsonDir[T any] struct {
+	dir   string
+	mutex sync.Mutex
+}
+
+func newJSONDir[T any](dir string) (*jsonDir[T], error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, goerr.Wrap(err, "failed to create directory").With("dir", dir)
+	}
+	return &jsonDir[T]{dir: dir}, nil
+}
+
+func (x *jsonDir[T]) path(key string) (string, error) {
+	if !validKey.MatchString(key) || strings.Contains(key, "..") {
+		return "", goerr.New("invalid key").With("key", key)
+	}
+	return filepath.Join(x.dir, key+fileExt), nil
+}
+
+// put writes a record atomically by renaming a temporary file
+func (x *jsonDir[T]) put(key string, v *T) error {
+	path, err := x.path(key)
+	if err != nil {
+		return err
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return goerr.Wrap(err, "failed to marshal record").With("key", key)
+	}
+
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	tmp, err := os.CreateTemp(x.dir, ".tmp-*")
+	if err != nil {
+		return goerr.Wrap(err, "failed to create temp file").With("dir", x.dir)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return goerr.Wrap(err, "failed to write record").With("path", tmp.Name())
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return goerr.Wrap(err, "failed to sync record").With("path", tmp.Name())
+	}
+	if err := tmp.Close(); err != nil {
+		return goerr.Wrap(err, "failed to close record").With("path", tmp.Name())
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return goerr.Wrap(err, "failed to rename record").With("path", path)
+	}
+
+	return nil
+}
+
+// get returns nil if the record does not exist
+func (x *jsonDir[T]) get(key string) (*T, error) {
+	path, err := x.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, goerr.Wrap(err, "failed to read record").With("path", path)
+	}
+
+	var v T
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, goerr.Wrap(err, "failed to unmarshal record").With("path", path)
+	}
+
+	return &v, nil
+}
+
+// delete does not return error if the record does not exist
+func (x *jsonDir[T]) delete(key string) error {
+	path, err := x.path(key)
+	if err != nil {
+		return err
+	}
+
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return goerr.Wrap(err, "failed to delete record").With("path", path)
+	}
+	return nil
+}
+
+// keys returns keys of all records in lexical order
+func (x *jsonDir[T]) keys() ([]string, error) {
+	entries, err := os.ReadDir(x.dir)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to read directory").With("dir", x.dir)
+	}
+
+	var keys []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, fileExt) || strings.HasPrefix(name, ".") {
+			continue
+		}
+		keys = append(keys, strings.TrimSuffix(name, fileExt))
+	}
+	sort.Strings(keys)
+
+	return keys, nil
+}
@@ -0,0 +1,132 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/nounify/pkg/infra/retry"
+)
+
+const (
+	// DefaultTimeout is a timeout of a single HTTP request to a notification service
+	DefaultTimeout = 10 * time.Second
+
+	// maxResponseSize is the maximum size of response body to be read
+	maxResponseSize = 1024 * 1024
+	// maxErrorBodySize is the maximum size of response body attached to an error
+	maxErrorBodySize = 1024
+)
+
+// StatusError is an error of HTTP response with non-2xx status code.
+type StatusError struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+func (x *StatusError) Error() string {
+	body := x.Body
+	if len(body) > maxErrorBodySize {
+		body = body[:maxErrorBodySize]
+	}
+	return fmt.Sprintf("status code %d: %s", x.StatusCode, string(body))
+}
+
+// Temporary returns true if the request may succeed by retry, i.e. 429 or 5xx.
+func (x *StatusError) Temporary() bool {
+	return x.StatusCode == http.StatusTooManyRequests || x.StatusCode >= 500
+}
+
+// New returns a HTTP client with DefaultTimeout.
+func New() *http.Client {
+	return &http.Client{Timeout: DefaultTimeout}
+}
+
+// Send performs the request and returns the response body if the status code is 2xx. Otherwise, *StatusError is returned. Network errors and responses with 429 or 5xx are marked by retry.Temporary with Retry-After header. URL of the request is not included in errors because it may have a secret, e.g. webhook URL.
+func Send(client *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		// Do not retry if the caller gave up
+		if req.Context().Err() != nil {
+			return nil, goerr.Wrap(req.Context().Err(), "HTTP request canceled")
+		}
+		// *url.Error has URL of the request, then only the cause is kept
+		cause := err
+		var uErr *url.Error
+		if errors.As(err, &uErr) {
+			cause = uErr.Err
+		}
+		return nil, retry.Temporary(goerr.Wrap(cause, "failed to send HTTP request").With("method", req.Method).With("host", req.URL.Host), 0)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, retry.Temporary(goerr.Wrap(err, "failed to read HTTP response").With("host", req.URL.Host), 0)
+	}
+
+	if resp.StatusCode < 200 || 299 < resp.StatusCode {
+		statusErr := &StatusError{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header,
+			Body:       body,
+		}
+		err := goerr.Wrap(statusErr, "unexpected HTTP status code").
+			With("method", req.Method).
+			With("host", req.URL.Host)
+
+		if statusErr.Temporary() {
+			return nil, retry.Temporary(err, ParseRetryAfter(resp.Header.Get("Retry-After")))
+		}
+		return nil, err
+	}
+
+	return body, nil
+}
+
+// PostJSON sends body encoded as JSON by POST method.
+func PostJSON(ctx context.Context, client *http.Client, endpoint string, body any) ([]byte, error) {
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to marshal request body")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(raw))
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create HTTP request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return Send(client, req)
+}
+
+// ParseRetryAfter parses Retry-After header as seconds or HTTP date. It returns 0 if the value is empty or invalid.
+func ParseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if sec, err := strconv.ParseFloat(value, 64); err == nil {
+		if sec <= 0 {
+			return 0
+		}
+		return time.Duration(sec * float64(time.Second))
+	}
+
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+
+	return 0
+}
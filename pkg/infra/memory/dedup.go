@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/m-mizutani/nounify/pkg/domain/interfaces"
+)
+
+// DedupStore is an in-memory implementation of interfaces.DedupStore. Keys are not shared with other instances and are lost at restart.
+type DedupStore struct {
+	keys      map[string]time.Time
+	mutex     sync.Mutex
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+var _ interfaces.DedupStore = &DedupStore{}
+
+// sweepInterval is interval to remove expired keys
+const sweepInterval = time.Minute
+
+func NewDedupStore() *DedupStore {
+	return &DedupStore{
+		keys: make(map[string]time.Time),
+		now:  time.Now,
+	}
+}
+
+func (x *DedupStore) Add(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	now := x.now()
+	if now.Sub(x.lastSweep) > sweepInterval {
+		for k, expiresAt := range x.keys {
+			if !now.Before(expiresAt) {
+				delete(x.keys, k)
+			}
+		}
+		x.lastSweep = now
+	}
+
+	if expiresAt, ok := x.keys[key]; ok && now.Before(expiresAt) {
+		return false, nil
+	}
+
+	x.keys[key] = now.Add(ttl)
+	return true, nil
+}
+
+func (x *DedupStore) Remove(ctx context.Context, key string) error {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	delete(x.keys, key)
+	return nil
+}
@@ -0,0 +1,29 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/nounify/pkg/infra/memory"
+)
+
+func TestDedupStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	store := memory.NewDedupStore()
+	store.SetNow(func() time.Time { return now })
+
+	gt.True(t, gt.R1(store.Add(ctx, "k1", time.Minute)).NoError(t))
+	gt.False(t, gt.R1(store.Add(ctx, "k1", time.Minute)).NoError(t))
+	gt.True(t, gt.R1(store.Add(ctx, "k2", time.Minute)).NoError(t))
+
+	// Removed key can be added again
+	gt.NoError(t, store.Remove(ctx, "k2"))
+	gt.True(t, gt.R1(store.Add(ctx, "k2", time.Minute)).NoError(t))
+
+	// Expired key can be added again
+	now = now.Add(2 * time.Minute)
+	gt.True(t, gt.R1(store.Add(ctx, "k1", time.Minute)).NoError(t))
+}
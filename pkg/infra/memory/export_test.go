@@ -0,0 +1,17 @@
+package memory
+
+import "time"
+
+func (x *DedupStore) SetNow(now func() time.Time) {
+	x.now = now
+}
+
+func (x *MessageRefStore) SetNow(now func() time.Time) {
+	x.now = now
+}
+
+func (x *MessageRefStore) Len() int {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+	return x.lru.Len()
+}
@@ -0,0 +1,132 @@
+package memory
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/m-mizutani/nounify/pkg/domain/interfaces"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+)
+
+const (
+	DefaultMessageRefTTL  = 7 * 24 * time.Hour
+	DefaultMaxMessageRefs = 10000
+)
+
+// MessageRefStore is an in-memory implementation of interfaces.MessageRefStore. References are lost at restart. A reference expires when it is not used for TTL, and the least recently used one is evicted when the number of references exceeds the limit.
+type MessageRefStore struct {
+	refs    map[string]*list.Element
+	lru     *list.List
+	ttl     time.Duration
+	maxRefs int
+	mutex   sync.Mutex
+	now     func() time.Time
+}
+
+type messageRefEntry struct {
+	key       string
+	ref       model.MessageRef
+	expiresAt time.Time
+}
+
+var _ interfaces.MessageRefStore = &MessageRefStore{}
+
+type MessageRefOption func(*MessageRefStore)
+
+// WithMessageRefTTL sets period to keep a reference since it is used last time. 0 disables expiration.
+func WithMessageRefTTL(ttl time.Duration) MessageRefOption {
+	return func(x *MessageRefStore) {
+		x.ttl = ttl
+	}
+}
+
+// WithMaxMessageRefs sets the maximum number of references. 0 disables the limit.
+func WithMaxMessageRefs(n int) MessageRefOption {
+	return func(x *MessageRefStore) {
+		x.maxRefs = n
+	}
+}
+
+func NewMessageRefStore(options ...MessageRefOption) *MessageRefStore {
+	x := &MessageRefStore{
+		refs:    make(map[string]*list.Element),
+		lru:     list.New(),
+		ttl:     DefaultMessageRefTTL,
+		maxRefs: DefaultMaxMessageRefs,
+		now:     time.Now,
+	}
+	for _, option := range options {
+		option(x)
+	}
+	return x
+}
+
+func (x *MessageRefStore) Get(ctx context.Context, key string) (*model.MessageRef, error) {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	now := x.now()
+	x.evictExpired(now)
+
+	elem, ok := x.refs[key]
+	if !ok {
+		return nil, nil
+	}
+
+	entry := elem.Value.(*messageRefEntry)
+	x.touch(elem, now)
+	ref := entry.ref
+	return &ref, nil
+}
+
+func (x *MessageRefStore) Put(ctx context.Context, key string, ref *model.MessageRef) error {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	now := x.now()
+	x.evictExpired(now)
+
+	if elem, ok := x.refs[key]; ok {
+		elem.Value.(*messageRefEntry).ref = *ref
+		x.touch(elem, now)
+		return nil
+	}
+
+	elem := x.lru.PushFront(&messageRefEntry{key: key, ref: *ref})
+	x.touch(elem, now)
+	x.refs[key] = elem
+
+	for x.maxRefs > 0 && x.lru.Len() > x.maxRefs {
+		x.remove(x.lru.Back())
+	}
+	return nil
+}
+
+// touch marks the reference as most recently used and extends its expiration
+func (x *MessageRefStore) touch(elem *list.Element, now time.Time) {
+	if x.ttl > 0 {
+		elem.Value.(*messageRefEntry).expiresAt = now.Add(x.ttl)
+	}
+	x.lru.MoveToFront(elem)
+}
+
+// evictExpired removes expired references from the least recently used one. References are ordered by expiration because TTL is extended on every use.
+func (x *MessageRefStore) evictExpired(now time.Time) {
+	if x.ttl <= 0 {
+		return
+	}
+
+	for elem := x.lru.Back(); elem != nil; elem = x.lru.Back() {
+		if now.Before(elem.Value.(*messageRefEntry).expiresAt) {
+			return
+		}
+		x.remove(elem)
+	}
+}
+
+func (x *MessageRefStore) remove(elem *list.Element) {
+	x.lru.Remove(elem)
+	delete(x.refs, elem.Value.(*messageRefEntry).key)
+}
@@ -0,0 +1,49 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+	"github.com/m-mizutani/nounify/pkg/infra/memory"
+)
+
+func TestMessageRefStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("expire unused reference", func(t *testing.T) {
+		now := time.Now()
+		store := memory.NewMessageRefStore(memory.WithMessageRefTTL(time.Hour))
+		store.SetNow(func() time.Time { return now })
+
+		gt.NoError(t, store.Put(ctx, "k1", &model.MessageRef{Channel: "C1", Timestamp: "1.1"}))
+		gt.NoError(t, store.Put(ctx, "k2", &model.MessageRef{Channel: "C2", Timestamp: "2.2"}))
+
+		// Using k1 extends its expiration
+		now = now.Add(40 * time.Minute)
+		ref := gt.R1(store.Get(ctx, "k1")).NoError(t)
+		gt.V(t, ref).NotNil().Must()
+		gt.Equal(t, ref.Timestamp, "1.1")
+
+		now = now.Add(40 * time.Minute)
+		gt.V(t, gt.R1(store.Get(ctx, "k1")).NoError(t)).NotNil()
+		gt.V(t, gt.R1(store.Get(ctx, "k2")).NoError(t)).Nil()
+		gt.Equal(t, store.Len(), 1)
+	})
+
+	t.Run("evict least recently used reference", func(t *testing.T) {
+		store := memory.NewMessageRefStore(memory.WithMaxMessageRefs(2))
+
+		gt.NoError(t, store.Put(ctx, "k1", &model.MessageRef{Timestamp: "1.1"}))
+		gt.NoError(t, store.Put(ctx, "k2", &model.MessageRef{Timestamp: "2.2"}))
+		gt.V(t, gt.R1(store.Get(ctx, "k1")).NoError(t)).NotNil()
+		gt.NoError(t, store.Put(ctx, "k3", &model.MessageRef{Timestamp: "3.3"}))
+
+		gt.Equal(t, store.Len(), 2)
+		gt.V(t, gt.R1(store.Get(ctx, "k1")).NoError(t)).NotNil()
+		gt.V(t, gt.R1(store.Get(ctx, "k2")).NoError(t)).Nil()
+		gt.V(t, gt.R1(store.Get(ctx, "k3")).NoError(t)).NotNil()
+	})
+}
@@ -0,0 +1,211 @@
+package pagerduty
+
+import (
+	"context"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/nounify/pkg/domain/interfaces"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+	"github.com/m-mizutani/nounify/pkg/domain/types"
+	"github.com/m-mizutani/nounify/pkg/infra/httpclient"
+)
+
+const (
+	defaultEndpoint = "https://events.pagerduty.com/v2/enqueue"
+	defaultSource   = "nounify"
+
+	maxSummaryLength = 1024
+)
+
+var (
+	eventActions = map[string]struct{}{
+		"trigger":     {},
+		"acknowledge": {},
+		"resolve":     {},
+	}
+	severities = map[string]struct{}{
+		"critical": {},
+		"error":    {},
+		"warning":  {},
+		"info":     {},
+	}
+)
+
+// Notifier sends events to PagerDuty Events API v2. Channel of the message is a name of the routing key.
+type Notifier struct {
+	routingKeys map[string]string
+	endpoint    string
+	client      *http.Client
+}
+
+var _ interfaces.Notifier = &Notifier{}
+
+type Option func(*Notifier)
+
+func WithHTTPClient(client *http.Client) Option {
+	return func(x *Notifier) {
+		x.client = client
+	}
+}
+
+// WithEndpoint replaces URL of Events API v2, e.g. for EU service region.
+func WithEndpoint(endpoint string) Option {
+	return func(x *Notifier) {
+		x.endpoint = endpoint
+	}
+}
+
+// New creates a PagerDuty notifier. routingKeys is a map of channel name to integration (routing) key.
+func New(routingKeys map[string]string, options ...Option) *Notifier {
+	x := &Notifier{
+		routingKeys: routingKeys,
+		endpoint:    defaultEndpoint,
+		client:      httpclient.New(),
+	}
+	for _, option := range options {
+		option(x)
+	}
+	return x
+}
+
+func (x *Notifier) Notify(ctx context.Context, msg model.Message) error {
+	routingKey, ok := x.routingKeys[msg.Channel]
+	if !ok {
+		return goerr.Wrap(types.ErrInvalidPolicyOutput, "PagerDuty routing key is not configured for the channel").With("channel", msg.Channel)
+	}
+
+	ev, err := buildEvent(routingKey, msg)
+	if err != nil {
+		return err
+	}
+
+	if _, err := httpclient.PostJSON(ctx, x.client, x.endpoint, ev); err != nil {
+		return goerr.Wrap(err, "failed to send event to PagerDuty").
+			With("channel", msg.Channel).
+			With("event_action", ev.EventAction).
+			With("dedup_key", ev.DedupKey)
+	}
+
+	return nil
+}
+
+type event struct {
+	RoutingKey  string   `json:"routing_key"`
+	EventAction string   `json:"event_action"`
+	DedupKey    string   `json:"dedup_key,omitempty"`
+	Client      string   `json:"client,omitempty"`
+	Payload     *payload `json:"payload,omitempty"`
+	Links       []link   `json:"links,omitempty"`
+}
+
+type payload struct {
+	Summary       string         `json:"summary"`
+	Source        string         `json:"source"`
+	Severity      string         `json:"severity"`
+	Component     string         `json:"component,omitempty"`
+	Group         string         `json:"group,omitempty"`
+	Class         string         `json:"class,omitempty"`
+	CustomDetails map[string]any `json:"custom_details,omitempty"`
+}
+
+type link struct {
+	Href string `json:"href"`
+	Text string `json:"text"`
+}
+
+func buildEvent(routingKey string, msg model.Message) (*event, error) {
+	var opt model.PagerDutyEvent
+	if msg.PagerDuty != nil {
+		opt = *msg.PagerDuty
+	}
+
+	action := opt.EventAction
+	if action == "" {
+		action = "trigger"
+	}
+	if _, ok := eventActions[action]; !ok {
+		return nil, goerr.Wrap(types.ErrInvalidPolicyOutput, "invalid event_action of PagerDuty").With("event_action", action)
+	}
+
+	ev := &event{
+		RoutingKey:  routingKey,
+		EventAction: action,
+		DedupKey:    msg.DedupKey,
+		Client:      defaultSource,
+	}
+
+	// acknowledge and resolve need only dedup_key to identify the alert
+	if action != "trigger" {
+		if msg.DedupKey == "" {
+			return nil, goerr.Wrap(types.ErrInvalidPolicyOutput, "dedup_key is required to acknowledge or resolve PagerDuty alert").With("event_action", action)
+		}
+		return ev, nil
+	}
+
+	severity := opt.Severity
+	if severity == "" {
+		severity = severityOfColor(msg.Color)
+	}
+	if _, ok := severities[severity]; !ok {
+		return nil, goerr.Wrap(types.ErrInvalidPolicyOutput, "invalid severity of PagerDuty").With("severity", severity)
+	}
+
+	summary := msg.Title
+	if summary == "" {
+		summary = msg.Body
+	}
+	if summary == "" {
+		return nil, goerr.Wrap(types.ErrInvalidPolicyOutput, "title or body is required for PagerDuty summary")
+	}
+
+	source := opt.Source
+	if source == "" {
+		source = defaultSource
+	}
+
+	details := make(map[string]any)
+	if msg.Title != "" && msg.Body != "" {
+		details["body"] = msg.Body
+	}
+	for _, field := range msg.Fields {
+		details[field.Name] = field.Value
+		if field.Link != "" {
+			ev.Links = append(ev.Links, link{Href: field.Link, Text: field.Name + ": " + field.Value})
+		}
+	}
+	if len(details) == 0 {
+		details = nil
+	}
+
+	ev.Payload = &payload{
+		Summary:       truncate(summary, maxSummaryLength),
+		Source:        source,
+		Severity:      severity,
+		Component:     opt.Component,
+		Group:         opt.Group,
+		Class:         opt.Class,
+		CustomDetails: details,
+	}
+
+	return ev, nil
+}
+
+func severityOfColor(color string) string {
+	switch color {
+	case model.ColorError:
+		return "error"
+	case model.ColorWarning:
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+func truncate(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return string([]rune(s)[:max-1]) + "…"
+}
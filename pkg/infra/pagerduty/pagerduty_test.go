@@ -0,0 +1,110 @@
+package pagerduty_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+	"github.com/m-mizutani/nounify/pkg/infra/pagerduty"
+	"github.com/m-mizutani/nounify/pkg/infra/retry"
+)
+
+func newServer(t *testing.T, status int, received *map[string]any) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gt.NoError(t, json.NewDecoder(r.Body).Decode(received))
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"status":"success","message":"Event processed","dedup_key":"x"}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestNotifyTrigger(t *testing.T) {
+	var received map[string]any
+	srv := newServer(t, http.StatusAccepted, &received)
+
+	notifier := pagerduty.New(map[string]string{"infra": "routing-key-1"}, pagerduty.WithEndpoint(srv.URL))
+	gt.NoError(t, notifier.Notify(context.Background(), model.Message{
+		Channel:  "infra",
+		Color:    "error",
+		Title:    "Database is down",
+		Body:     "Primary DB does not respond",
+		DedupKey: "db-primary",
+		Fields: []model.MessageField{
+			{Name: "Region", Value: "us-east-1"},
+			{Name: "Dashboard", Value: "grafana", Link: "https://grafana.example.com"},
+		},
+		PagerDuty: &model.PagerDutyEvent{Component: "postgres"},
+	}))
+
+	gt.Equal(t, received["routing_key"], "routing-key-1")
+	gt.Equal(t, received["event_action"], "trigger")
+	gt.Equal(t, received["dedup_key"], "db-primary")
+
+	payload := received["payload"].(map[string]any)
+	gt.Equal(t, payload["summary"], "Database is down")
+	gt.Equal(t, payload["severity"], "error")
+	gt.Equal(t, payload["source"], "nounify")
+	gt.Equal(t, payload["component"], "postgres")
+
+	details := payload["custom_details"].(map[string]any)
+	gt.Equal(t, details["body"], "Primary DB does not respond")
+	gt.Equal(t, details["Region"], "us-east-1")
+
+	links := received["links"].([]any)
+	gt.A(t, links).Length(1)
+	gt.Equal(t, links[0].(map[string]any)["href"], "https://grafana.example.com")
+}
+
+func TestNotifyResolve(t *testing.T) {
+	var received map[string]any
+	srv := newServer(t, http.StatusAccepted, &received)
+
+	notifier := pagerduty.New(map[string]string{"infra": "routing-key-1"}, pagerduty.WithEndpoint(srv.URL))
+	gt.NoError(t, notifier.Notify(context.Background(), model.Message{
+		Channel:   "infra",
+		Title:     "Database is up",
+		DedupKey:  "db-primary",
+		PagerDuty: &model.PagerDutyEvent{EventAction: "resolve"},
+	}))
+
+	gt.Equal(t, received["event_action"], "resolve")
+	gt.Equal(t, received["dedup_key"], "db-primary")
+	_, hasPayload := received["payload"]
+	gt.False(t, hasPayload)
+}
+
+func TestNotifyInvalid(t *testing.T) {
+	notifier := pagerduty.New(map[string]string{"infra": "routing-key-1"}, pagerduty.WithEndpoint("http://127.0.0.1:0"))
+
+	testCases := map[string]model.Message{
+		"unknown routing key":     {Channel: "unknown", Title: "x"},
+		"invalid event action":    {Channel: "infra", Title: "x", PagerDuty: &model.PagerDutyEvent{EventAction: "escalate"}},
+		"invalid severity":        {Channel: "infra", Title: "x", PagerDuty: &model.PagerDutyEvent{Severity: "fatal"}},
+		"resolve without dedup":   {Channel: "infra", PagerDuty: &model.PagerDutyEvent{EventAction: "resolve"}},
+		"trigger without summary": {Channel: "infra"},
+	}
+	for title, msg := range testCases {
+		t.Run(title, func(t *testing.T) {
+			err := notifier.Notify(context.Background(), msg)
+			gt.Error(t, err)
+			retryable, _ := retry.IsTemporary(err)
+			gt.False(t, retryable)
+		})
+	}
+}
+
+func TestNotifyThrottled(t *testing.T) {
+	var received map[string]any
+	srv := newServer(t, http.StatusTooManyRequests, &received)
+
+	notifier := pagerduty.New(map[string]string{"infra": "routing-key-1"}, pagerduty.WithEndpoint(srv.URL))
+	err := notifier.Notify(context.Background(), model.Message{Channel: "infra", Title: "x"})
+	gt.Error(t, err)
+	retryable, _ := retry.IsTemporary(err)
+	gt.True(t, retryable)
+}
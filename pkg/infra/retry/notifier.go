@@ -0,0 +1,38 @@
+package retry
+
+import (
+	"context"
+	"time"
+
+	"github.com/m-mizutani/nounify/pkg/domain/interfaces"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+	"github.com/m-mizutani/nounify/pkg/domain/types"
+	"github.com/m-mizutani/nounify/pkg/utils/ctxutil"
+)
+
+// Notifier wraps interfaces.Notifier and retries errors marked by Temporary.
+type Notifier struct {
+	notifier interfaces.Notifier
+	dest     types.Destination
+	cfg      Config
+}
+
+var _ interfaces.Notifier = &Notifier{}
+
+func NewNotifier(notifier interfaces.Notifier, dest types.Destination, cfg Config) *Notifier {
+	return &Notifier{notifier: notifier, dest: dest, cfg: cfg}
+}
+
+func (x *Notifier) Notify(ctx context.Context, msg model.Message) error {
+	return Do(ctx, x.cfg, IsTemporary, func(ctx context.Context) error {
+		return x.notifier.Notify(ctx, msg)
+	}, func(attempt int, wait time.Duration, err error) {
+		ctxutil.Logger(ctx).Warn("retry notification",
+			"destination", x.dest,
+			"channel", msg.Channel,
+			"attempt", attempt,
+			"wait", wait,
+			"error", err,
+		)
+	})
+}
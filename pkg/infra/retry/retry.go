@@ -0,0 +1,118 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/m-mizutani/goerr"
+)
+
+// Config is a retry policy with jittered exponential backoff.
+type Config struct {
+	// MaxAttempts is the maximum number of attempts including the first one. 0 or 1 disables retry.
+	MaxAttempts int
+	// Interval is the base wait before the second attempt. It is doubled for every attempt.
+	Interval time.Duration
+	// MaxInterval caps a single wait.
+	MaxInterval time.Duration
+	// Budget is the total time allowed for all attempts and waits. 0 means no limit.
+	Budget time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{
+		MaxAttempts: 5,
+		Interval:    500 * time.Millisecond,
+		MaxInterval: 30 * time.Second,
+		Budget:      time.Minute,
+	}
+}
+
+// Classifier decides whether err is transient. When wait is greater than 0, it is used instead of the backoff (e.g. Retry-After).
+type Classifier func(err error) (retryable bool, wait time.Duration)
+
+type temporaryError struct {
+	err   error
+	after time.Duration
+}
+
+func (x *temporaryError) Error() string { return x.err.Error() }
+func (x *temporaryError) Unwrap() error { return x.err }
+
+// Temporary marks err as retryable. after is the wait requested by the remote side, 0 if not specified.
+func Temporary(err error, after time.Duration) error {
+	return &temporaryError{err: err, after: after}
+}
+
+// IsTemporary is a Classifier for errors marked by Temporary.
+func IsTemporary(err error) (bool, time.Duration) {
+	var tmpErr *temporaryError
+	if errors.As(err, &tmpErr) {
+		return true, tmpErr.after
+	}
+	return false, 0
+}
+
+func (x Config) backoff(attempt int) time.Duration {
+	wait := x.Interval
+	for i := 1; i < attempt && wait < x.MaxInterval; i++ {
+		wait *= 2
+	}
+	if x.MaxInterval > 0 && wait > x.MaxInterval {
+		wait = x.MaxInterval
+	}
+	if wait <= 0 {
+		return 0
+	}
+
+	// Equal jitter: keep half of the wait and randomize the other half
+	half := wait / 2
+	return half + rand.N(wait-half+1)
+}
+
+// Do calls fn until it succeeds, returns a non-retryable error, or the attempts or budget are exhausted. onRetry is called before every wait if not nil.
+func Do(ctx context.Context, cfg Config, classify Classifier, fn func(ctx context.Context) error, onRetry func(attempt int, wait time.Duration, err error)) error {
+	var deadline time.Time
+	if cfg.Budget > 0 {
+		deadline = time.Now().Add(cfg.Budget)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+
+		retryable, wait := classify(err)
+		if !retryable {
+			return err
+		}
+		if attempt >= cfg.MaxAttempts {
+			return goerr.Wrap(err, "retry attempts exhausted").With("attempts", attempt)
+		}
+
+		if wait <= 0 {
+			wait = cfg.backoff(attempt)
+		}
+		if !deadline.IsZero() && time.Now().Add(wait).After(deadline) {
+			return goerr.Wrap(err, "retry budget exhausted").
+				With("attempts", attempt).
+				With("budget", cfg.Budget).
+				With("wait", wait)
+		}
+
+		if onRetry != nil {
+			onRetry(attempt, wait, err)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return goerr.Wrap(ctx.Err(), "retry canceled").With("attempts", attempt).With("last_error", err.Error())
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/m-mizutani/nounify/pkg/domain/interfaces"
+	"github.com/m-mizutani/nounify/pkg/utils/ctxutil"
+	"github.com/slack-go/slack"
+)
+
+// Slack wraps interfaces.Slack and retries transient failures of Slack API.
+type Slack struct {
+	client interfaces.Slack
+	cfg    Config
+}
+
+var _ interfaces.Slack = &Slack{}
+
+func NewSlack(client interfaces.Slack, cfg Config) *Slack {
+	return &Slack{client: client, cfg: cfg}
+}
+
+// retryableSlackErrors is a list of Slack API error codes that can be resolved by retry.
+var retryableSlackErrors = map[string]struct{}{
+	"ratelimited":         {},
+	"rate_limited":        {},
+	"internal_error":      {},
+	"fatal_error":         {},
+	"service_unavailable": {},
+	"request_timeout":     {},
+}
+
+// IsSlackTemporary is a Classifier for errors of slack-go client.
+func IsSlackTemporary(err error) (bool, time.Duration) {
+	var rateErr *slack.RateLimitedError
+	if errors.As(err, &rateErr) {
+		return true, rateErr.RetryAfter
+	}
+
+	var statusErr slack.StatusCodeError
+	if errors.As(err, &statusErr) {
+		return statusErr.Retryable(), 0
+	}
+
+	var respErr slack.SlackErrorResponse
+	if errors.As(err, &respErr) {
+		_, ok := retryableSlackErrors[respErr.Err]
+		return ok, 0
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false, 0
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true, 0
+	}
+
+	return IsTemporary(err)
+}
+
+func (x *Slack) do(ctx context.Context, channelID, action string, fn func(ctx context.Context) error) error {
+	return Do(ctx, x.cfg, IsSlackTemporary, fn, func(attempt int, wait time.Duration, err error) {
+		ctxutil.Logger(ctx).Warn("retry "+action,
+			"channel", channelID,
+			"attempt", attempt,
+			"wait", wait,
+			"error", err,
+		)
+	})
+}
+
+func (x *Slack) PostMessageContext(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
+	var respChannel, respTimestamp string
+
+	err := x.do(ctx, channelID, "posting Slack message", func(ctx context.Context) error {
+		ch, ts, err := x.client.PostMessageContext(ctx, channelID, options...)
+		if err != nil {
+			return err
+		}
+		respChannel, respTimestamp = ch, ts
+		return nil
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	return respChannel, respTimestamp, nil
+}
+
+func (x *Slack) UpdateMessageContext(ctx context.Context, channelID, timestamp string, options ...slack.MsgOption) (string, string, string, error) {
+	var respChannel, respTimestamp, respText string
+
+	err := x.do(ctx, channelID, "updating Slack message", func(ctx context.Context) error {
+		ch, ts, text, err := x.client.UpdateMessageContext(ctx, channelID, timestamp, options...)
+		if err != nil {
+			return err
+		}
+		respChannel, respTimestamp, respText = ch, ts, text
+		return nil
+	})
+	if err != nil {
+		return "", "", "", err
+	}
+
+	return respChannel, respTimestamp, respText, nil
+}
+
+// UploadFileV2Context retries the whole upload flow. The file must be given by Content or File because Reader can not be read again.
+func (x *Slack) UploadFileV2Context(ctx context.Context, params slack.UploadFileV2Parameters) (*slack.FileSummary, error) {
+	var file *slack.FileSummary
+
+	err := x.do(ctx, params.Channel, "uploading Slack file", func(ctx context.Context) error {
+		f, err := x.client.UploadFileV2Context(ctx, params)
+		if err != nil {
+			return err
+		}
+		file = f
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
@@ -0,0 +1,121 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/nounify/pkg/domain/mock"
+	"github.com/m-mizutani/nounify/pkg/infra/retry"
+	"github.com/slack-go/slack"
+)
+
+func TestSlackRetry(t *testing.T) {
+	cfg := retry.Config{
+		MaxAttempts: 3,
+		Interval:    time.Millisecond,
+		MaxInterval: 10 * time.Millisecond,
+		Budget:      time.Second,
+	}
+
+	type testCase struct {
+		errs     []error
+		cfg      retry.Config
+		expErr   bool
+		expCalls int
+	}
+
+	runTest := func(tc testCase) func(t *testing.T) {
+		return func(t *testing.T) {
+			slackMock := &mock.SlackMock{}
+			slackMock.PostMessageContextFunc = func(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
+				n := len(slackMock.PostMessageContextCalls()) - 1
+				if n < len(tc.errs) && tc.errs[n] != nil {
+					return "", "", tc.errs[n]
+				}
+				return "C0123", "1234.5678", nil
+			}
+
+			client := retry.NewSlack(slackMock, tc.cfg)
+			ch, ts, err := client.PostMessageContext(context.Background(), "test")
+			if tc.expErr {
+				gt.Error(t, err)
+			} else {
+				gt.NoError(t, err)
+				gt.Equal(t, ch, "C0123")
+				gt.Equal(t, ts, "1234.5678")
+			}
+			gt.A(t, slackMock.PostMessageContextCalls()).Length(tc.expCalls)
+		}
+	}
+
+	t.Run("success without retry", runTest(testCase{
+		cfg:      cfg,
+		expCalls: 1,
+	}))
+
+	t.Run("retry on rate limit", runTest(testCase{
+		errs: []error{
+			&slack.RateLimitedError{RetryAfter: time.Millisecond},
+		},
+		cfg:      cfg,
+		expCalls: 2,
+	}))
+
+	t.Run("retry on server error", runTest(testCase{
+		errs: []error{
+			slack.StatusCodeError{Code: http.StatusBadGateway, Status: "502 Bad Gateway"},
+			slack.SlackErrorResponse{Err: "internal_error"},
+		},
+		cfg:      cfg,
+		expCalls: 3,
+	}))
+
+	t.Run("give up after max attempts", runTest(testCase{
+		errs: []error{
+			slack.StatusCodeError{Code: http.StatusServiceUnavailable},
+			slack.StatusCodeError{Code: http.StatusServiceUnavailable},
+			slack.StatusCodeError{Code: http.StatusServiceUnavailable},
+		},
+		cfg:      cfg,
+		expErr:   true,
+		expCalls: 3,
+	}))
+
+	t.Run("do not retry permanent error", runTest(testCase{
+		errs: []error{
+			slack.SlackErrorResponse{Err: "channel_not_found"},
+		},
+		cfg:      cfg,
+		expErr:   true,
+		expCalls: 1,
+	}))
+
+	t.Run("give up when Retry-After exceeds budget", runTest(testCase{
+		errs: []error{
+			&slack.RateLimitedError{RetryAfter: time.Minute},
+		},
+		cfg:      cfg,
+		expErr:   true,
+		expCalls: 1,
+	}))
+}
+
+func TestDoCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cfg := retry.Config{MaxAttempts: 10, Interval: time.Hour, MaxInterval: time.Hour}
+
+	var calls int
+	err := retry.Do(ctx, cfg, retry.IsTemporary, func(ctx context.Context) error {
+		calls++
+		return retry.Temporary(errors.New("temporary"), 0)
+	}, func(attempt int, wait time.Duration, err error) {
+		cancel()
+	})
+	gt.Error(t, err)
+	gt.True(t, errors.Is(err, context.Canceled))
+	gt.Equal(t, calls, 1)
+}
@@ -0,0 +1,108 @@
+package slackwebhook
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/nounify/pkg/domain/interfaces"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+	"github.com/m-mizutani/nounify/pkg/domain/types"
+	"github.com/m-mizutani/nounify/pkg/infra/httpclient"
+	"github.com/m-mizutani/nounify/pkg/utils/slackmsg"
+	"github.com/slack-go/slack"
+)
+
+// Notifier posts messages to Slack compatible incoming webhooks. Channel of the message is a name of the webhook.
+type Notifier struct {
+	webhooks map[string]string
+	legacy   bool
+	client   *http.Client
+}
+
+var _ interfaces.Notifier = &Notifier{}
+
+type Option func(*Notifier)
+
+func WithHTTPClient(client *http.Client) Option {
+	return func(x *Notifier) {
+		x.client = client
+	}
+}
+
+// WithLegacyAttachment renders messages with legacy attachment fields instead of Block Kit for services such as Mattermost and Rocket.Chat.
+func WithLegacyAttachment() Option {
+	return func(x *Notifier) {
+		x.legacy = true
+	}
+}
+
+// New creates a notifier for Slack compatible incoming webhooks. webhooks is a map of channel name to webhook URL.
+func New(webhooks map[string]string, options ...Option) *Notifier {
+	x := &Notifier{
+		webhooks: webhooks,
+		client:   httpclient.New(),
+	}
+	for _, option := range options {
+		option(x)
+	}
+	return x
+}
+
+type payload struct {
+	IconURL   string `json:"icon_url,omitempty"`
+	IconEmoji string `json:"icon_emoji,omitempty"`
+	// Avatar and Emoji are icon fields of Rocket.Chat
+	Avatar      string             `json:"avatar,omitempty"`
+	Emoji       string             `json:"emoji,omitempty"`
+	Text        string             `json:"text,omitempty"`
+	Blocks      []slack.Block      `json:"blocks,omitempty"`
+	Attachments []slack.Attachment `json:"attachments,omitempty"`
+}
+
+func (x *Notifier) Notify(ctx context.Context, msg model.Message) error {
+	url, ok := x.webhooks[msg.Channel]
+	if !ok {
+		return goerr.Wrap(types.ErrInvalidPolicyOutput, "incoming webhook is not configured for the channel").With("channel", msg.Channel)
+	}
+
+	p, err := x.buildPayload(msg)
+	if err != nil {
+		return err
+	}
+
+	if _, err := httpclient.PostJSON(ctx, x.client, url, p); err != nil {
+		return goerr.Wrap(err, "failed to post message to incoming webhook").With("channel", msg.Channel)
+	}
+
+	return nil
+}
+
+// buildPayload renders the message. Raw blocks are ignored in legacy mode because the services do not support Block Kit.
+func (x *Notifier) buildPayload(msg model.Message) (*payload, error) {
+	var p payload
+
+	if x.legacy {
+		p.Attachments = []slack.Attachment{slackmsg.LegacyAttachment(msg)}
+	} else {
+		content, err := slackmsg.NewContent(msg)
+		if err != nil {
+			return nil, err
+		}
+		p.Text = content.Text
+		p.Blocks = content.Blocks
+		p.Attachments = content.Attachments
+	}
+
+	if msg.Emoji != "" { // Emoji has higher priority than Icon
+		p.IconEmoji = msg.Emoji
+	} else if msg.Icon != "" {
+		p.IconURL = msg.Icon
+	}
+	if x.legacy {
+		p.Emoji = p.IconEmoji
+		p.Avatar = p.IconURL
+	}
+
+	return &p, nil
+}
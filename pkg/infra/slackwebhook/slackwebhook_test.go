@@ -0,0 +1,76 @@
+package slackwebhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+	"github.com/m-mizutani/nounify/pkg/infra/slackwebhook"
+)
+
+func TestNotifyLegacy(t *testing.T) {
+	var received map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gt.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer srv.Close()
+
+	notifier := slackwebhook.New(map[string]string{"town-square": srv.URL}, slackwebhook.WithLegacyAttachment())
+	gt.NoError(t, notifier.Notify(context.Background(), model.Message{
+		Channel: "town-square",
+		Color:   "error",
+		Title:   "Build failed",
+		Body:    "main branch is broken",
+		Icon:    "https://example.com/icon.png",
+		Fields: []model.MessageField{
+			{Name: "Job", Value: "test", Link: "https://ci.example.com/1"},
+		},
+	}))
+
+	gt.Equal(t, received["icon_url"], "https://example.com/icon.png")
+	gt.Equal(t, received["avatar"], "https://example.com/icon.png")
+
+	att := received["attachments"].([]any)[0].(map[string]any)
+	gt.Equal(t, att["color"], "#FF0000")
+	gt.Equal(t, att["title"], "Build failed")
+	gt.Equal(t, att["text"], "main branch is broken")
+	gt.Equal(t, att["fallback"], "Build failed")
+	gt.True(t, att["blocks"] == nil)
+
+	field := att["fields"].([]any)[0].(map[string]any)
+	gt.Equal(t, field["title"], "Job")
+	gt.Equal(t, field["value"], "[test](https://ci.example.com/1)")
+	gt.Equal(t, field["short"], true)
+}
+
+func TestNotifyBlockKit(t *testing.T) {
+	var received map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gt.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer srv.Close()
+
+	notifier := slackwebhook.New(map[string]string{"alerts": srv.URL})
+	gt.NoError(t, notifier.Notify(context.Background(), model.Message{
+		Channel: "alerts",
+		Title:   "Build failed",
+		Emoji:   ":fire:",
+	}))
+
+	gt.Equal(t, received["icon_emoji"], ":fire:")
+	_, hasEmoji := received["emoji"]
+	gt.False(t, hasEmoji)
+
+	att := received["attachments"].([]any)[0].(map[string]any)
+	gt.Equal(t, att["color"], "#2EB67D")
+	gt.A(t, att["blocks"].([]any)).Length(2)
+}
+
+func TestNotifyUnknownChannel(t *testing.T) {
+	notifier := slackwebhook.New(map[string]string{"alerts": "https://example.com"})
+	gt.Error(t, notifier.Notify(context.Background(), model.Message{Channel: "unknown"}))
+}
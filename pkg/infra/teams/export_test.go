@@ -0,0 +1,3 @@
+package teams
+
+var StyleOfColorCode = styleOfColorCode
@@ -0,0 +1,223 @@
+package teams
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/nounify/pkg/domain/interfaces"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+	"github.com/m-mizutani/nounify/pkg/domain/types"
+	"github.com/m-mizutani/nounify/pkg/infra/httpclient"
+)
+
+// Notifier posts messages as Adaptive Card to Microsoft Teams incoming webhooks. Channel of the message is a name of the webhook.
+type Notifier struct {
+	webhooks map[string]string
+	client   *http.Client
+}
+
+var _ interfaces.Notifier = &Notifier{}
+
+type Option func(*Notifier)
+
+func WithHTTPClient(client *http.Client) Option {
+	return func(x *Notifier) {
+		x.client = client
+	}
+}
+
+// New creates a Teams notifier. webhooks is a map of channel name to incoming webhook URL.
+func New(webhooks map[string]string, options ...Option) *Notifier {
+	x := &Notifier{
+		webhooks: webhooks,
+		client:   httpclient.New(),
+	}
+	for _, option := range options {
+		option(x)
+	}
+	return x
+}
+
+func (x *Notifier) Notify(ctx context.Context, msg model.Message) error {
+	url, ok := x.webhooks[msg.Channel]
+	if !ok {
+		return goerr.Wrap(types.ErrInvalidPolicyOutput, "Teams webhook is not configured for the channel").With("channel", msg.Channel)
+	}
+
+	if _, err := httpclient.PostJSON(ctx, x.client, url, buildPayload(msg)); err != nil {
+		return goerr.Wrap(err, "failed to post message to Teams").With("channel", msg.Channel)
+	}
+
+	return nil
+}
+
+type payload struct {
+	Type        string       `json:"type"`
+	Attachments []attachment `json:"attachments"`
+}
+
+type attachment struct {
+	ContentType string `json:"contentType"`
+	Content     card   `json:"content"`
+}
+
+type card struct {
+	Schema  string            `json:"$schema"`
+	Type    string            `json:"type"`
+	Version string            `json:"version"`
+	Body    []element         `json:"body"`
+	MSTeams map[string]string `json:"msteams"`
+}
+
+type element map[string]any
+
+func buildPayload(msg model.Message) payload {
+	return payload{
+		Type: "message",
+		Attachments: []attachment{
+			{
+				ContentType: "application/vnd.microsoft.card.adaptive",
+				Content:     buildCard(msg),
+			},
+		},
+	}
+}
+
+// buildCard renders the message in the same layout as Slack attachment. Adaptive Card does not support any color code, then the color is converted to the nearest container style.
+func buildCard(msg model.Message) card {
+	var items []element
+
+	if msg.Title != "" {
+		title := element{
+			"type":   "TextBlock",
+			"text":   msg.Title,
+			"size":   "Large",
+			"weight": "Bolder",
+			"wrap":   true,
+		}
+		if msg.Icon != "" {
+			items = append(items, element{
+				"type": "ColumnSet",
+				"columns": []element{
+					{
+						"type":  "Column",
+						"width": "auto",
+						"items": []element{
+							{"type": "Image", "url": msg.Icon, "size": "Small"},
+						},
+					},
+					{
+						"type":                     "Column",
+						"width":                    "stretch",
+						"verticalContentAlignment": "Center",
+						"items":                    []element{title},
+					},
+				},
+			})
+		} else {
+			items = append(items, title)
+		}
+	}
+
+	if msg.Body != "" {
+		items = append(items, element{
+			"type": "TextBlock",
+			"text": msg.Body,
+			"wrap": true,
+		})
+	}
+
+	if len(msg.Fields) > 0 {
+		facts := make([]element, len(msg.Fields))
+		for i, field := range msg.Fields {
+			value := field.Value
+			if field.Link != "" {
+				value = "[" + field.Value + "](" + field.Link + ")"
+			}
+			facts[i] = element{"title": field.Name, "value": value}
+		}
+		items = append(items, element{
+			"type":  "FactSet",
+			"facts": facts,
+		})
+	}
+
+	return card{
+		Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+		Type:    "AdaptiveCard",
+		Version: "1.4",
+		Body: []element{
+			{
+				"type":  "Container",
+				"style": containerStyle(msg),
+				"bleed": true,
+				"items": items,
+			},
+		},
+		MSTeams: map[string]string{"width": "Full"},
+	}
+}
+
+func containerStyle(msg model.Message) string {
+	switch msg.Color {
+	case "", model.ColorInfo:
+		return "good"
+	case model.ColorWarning:
+		return "warning"
+	case model.ColorError:
+		return "attention"
+	}
+
+	return styleOfColorCode(msg.ColorCode())
+}
+
+// styleOfColorCode classifies a color code such as "#FF8800" by hue
+func styleOfColorCode(code string) string {
+	code = strings.TrimPrefix(code, "#")
+	if len(code) != 6 {
+		return "accent"
+	}
+	rgb, err := strconv.ParseUint(code, 16, 32)
+	if err != nil {
+		return "accent"
+	}
+
+	r := float64(rgb>>16&0xFF) / 255
+	g := float64(rgb>>8&0xFF) / 255
+	b := float64(rgb&0xFF) / 255
+	maxV := math.Max(r, math.Max(g, b))
+	minV := math.Min(r, math.Min(g, b))
+	delta := maxV - minV
+
+	if maxV == 0 || delta/maxV < 0.2 {
+		return "emphasis" // gray
+	}
+
+	var hue float64
+	switch maxV {
+	case r:
+		hue = math.Mod((g-b)/delta, 6) * 60
+	case g:
+		hue = ((b-r)/delta + 2) * 60
+	default:
+		hue = ((r-g)/delta + 4) * 60
+	}
+	if hue < 0 {
+		hue += 360
+	}
+
+	switch {
+	case hue < 20 || hue >= 330:
+		return "attention"
+	case hue < 70:
+		return "warning"
+	case hue < 170:
+		return "good"
+	default:
+		return "accent"
+	}
+}
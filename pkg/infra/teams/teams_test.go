@@ -0,0 +1,103 @@
+package teams_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+	"github.com/m-mizutani/nounify/pkg/infra/retry"
+	"github.com/m-mizutani/nounify/pkg/infra/teams"
+)
+
+func TestNotify(t *testing.T) {
+	var received map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gt.Equal(t, r.Header.Get("Content-Type"), "application/json")
+		gt.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	notifier := teams.New(map[string]string{"ops": srv.URL})
+	gt.NoError(t, notifier.Notify(context.Background(), model.Message{
+		Channel: "ops",
+		Color:   "error",
+		Title:   "Alert",
+		Body:    "something happened",
+		Icon:    "https://example.com/icon.png",
+		Fields: []model.MessageField{
+			{Name: "Severity", Value: "high"},
+			{Name: "Link", Value: "console", Link: "https://example.com"},
+		},
+	}))
+
+	gt.Equal(t, received["type"], "message")
+	att := received["attachments"].([]any)[0].(map[string]any)
+	gt.Equal(t, att["contentType"], "application/vnd.microsoft.card.adaptive")
+
+	card := att["content"].(map[string]any)
+	gt.Equal(t, card["type"], "AdaptiveCard")
+
+	container := card["body"].([]any)[0].(map[string]any)
+	gt.Equal(t, container["style"], "attention")
+
+	items := container["items"].([]any)
+	gt.A(t, items).Length(3)
+
+	header := items[0].(map[string]any)
+	gt.Equal(t, header["type"], "ColumnSet")
+	columns := header["columns"].([]any)
+	icon := columns[0].(map[string]any)["items"].([]any)[0].(map[string]any)
+	gt.Equal(t, icon["url"], "https://example.com/icon.png")
+	title := columns[1].(map[string]any)["items"].([]any)[0].(map[string]any)
+	gt.Equal(t, title["text"], "Alert")
+
+	body := items[1].(map[string]any)
+	gt.Equal(t, body["text"], "something happened")
+
+	facts := items[2].(map[string]any)["facts"].([]any)
+	gt.Equal(t, facts[0].(map[string]any)["title"], "Severity")
+	gt.Equal(t, facts[1].(map[string]any)["value"], "[console](https://example.com)")
+}
+
+func TestNotifyUnknownChannel(t *testing.T) {
+	notifier := teams.New(map[string]string{"ops": "https://example.com"})
+	gt.Error(t, notifier.Notify(context.Background(), model.Message{Channel: "dev"}))
+}
+
+func TestNotifyRateLimited(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	notifier := teams.New(map[string]string{"ops": srv.URL})
+	err := notifier.Notify(context.Background(), model.Message{Channel: "ops"})
+	gt.Error(t, err)
+
+	retryable, wait := retry.IsTemporary(err)
+	gt.True(t, retryable)
+	gt.Equal(t, wait, 3*time.Second)
+}
+
+func TestStyleOfColorCode(t *testing.T) {
+	testCases := map[string]string{
+		"#FF0000": "attention",
+		"#FFA500": "warning",
+		"#2EB67D": "good",
+		"#0000FF": "accent",
+		"#808080": "emphasis",
+		"invalid": "accent",
+	}
+	for code, expected := range testCases {
+		t.Run(code, func(t *testing.T) {
+			gt.Equal(t, teams.StyleOfColorCode(code), expected)
+		})
+	}
+}
@@ -0,0 +1,7 @@
+package webhook
+
+import "time"
+
+func (x *Notifier) SetNow(now func() time.Time) {
+	x.now = now
+}
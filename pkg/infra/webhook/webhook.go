@@ -0,0 +1,253 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/nounify/pkg/domain/interfaces"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+	"github.com/m-mizutani/nounify/pkg/domain/types"
+	"github.com/m-mizutani/nounify/pkg/infra/httpclient"
+)
+
+const (
+	// HeaderTimestamp is unix time when the request is signed
+	HeaderTimestamp = "X-Nounify-Timestamp"
+	// HeaderSignature is "sha256=" + hex encoded HMAC-SHA256 of "<timestamp>.<body>"
+	HeaderSignature = "X-Nounify-Signature"
+)
+
+var allowedMethods = map[string]struct{}{
+	http.MethodPost:  {},
+	http.MethodPut:   {},
+	http.MethodPatch: {},
+}
+
+// errLocalAddress is returned by dialer when the destination is a loopback or link-local address
+var errLocalAddress = errors.New("webhook to local address is not allowed")
+
+// Notifier sends a HTTP request specified by webhook of the message. URL from the policy may be built from the request, then loopback and link-local addresses (e.g. cloud metadata endpoint) are rejected by default to prevent SSRF.
+type Notifier struct {
+	client       *http.Client
+	secret       []byte
+	allowedHosts []string
+	allowLocal   bool
+	now          func() time.Time
+}
+
+var _ interfaces.Notifier = &Notifier{}
+
+type Option func(*Notifier)
+
+func WithHTTPClient(client *http.Client) Option {
+	return func(x *Notifier) {
+		x.client = client
+	}
+}
+
+// WithSecret enables HMAC signature of the request body in HeaderSignature.
+func WithSecret(secret string) Option {
+	return func(x *Notifier) {
+		x.secret = []byte(secret)
+	}
+}
+
+// WithAllowedHosts restricts hosts of webhook URL. A pattern can have wildcard, e.g. *.example.com. Any host is allowed if not set.
+func WithAllowedHosts(patterns ...string) Option {
+	return func(x *Notifier) {
+		for _, pattern := range patterns {
+			x.allowedHosts = append(x.allowedHosts, strings.ToLower(pattern))
+		}
+	}
+}
+
+// WithLocalAddressAllowed allows requests to loopback and link-local addresses, e.g. a receiver running as sidecar.
+func WithLocalAddressAllowed() Option {
+	return func(x *Notifier) {
+		x.allowLocal = true
+	}
+}
+
+func New(options ...Option) *Notifier {
+	x := &Notifier{
+		client: httpclient.New(),
+		now:    time.Now,
+	}
+	for _, option := range options {
+		option(x)
+	}
+
+	if !x.allowLocal {
+		x.client = denyLocalAddress(x.client)
+	}
+	return x
+}
+
+// denyLocalAddress returns a copy of the client that refuses to connect to loopback and link-local addresses. The address is checked after name resolution, then it can not be bypassed by DNS records pointing to the local address.
+func denyLocalAddress(client *http.Client) *http.Client {
+	base, ok := client.Transport.(*http.Transport)
+	if client.Transport == nil {
+		base, ok = http.DefaultTransport.(*http.Transport)
+	}
+	if !ok {
+		// Custom transport is responsible for its destination
+		return client
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip != nil && isLocalAddress(ip) {
+				return errLocalAddress
+			}
+			return nil
+		},
+	}
+
+	transport := base.Clone()
+	transport.DialContext = dialer.DialContext
+
+	restricted := *client
+	restricted.Transport = transport
+	return &restricted
+}
+
+func isLocalAddress(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified()
+}
+
+func (x *Notifier) hostAllowed(host string) bool {
+	if len(x.allowedHosts) == 0 {
+		return true
+	}
+
+	host = strings.ToLower(host)
+	for _, pattern := range x.allowedHosts {
+		if ok, _ := path.Match(pattern, host); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// messageBody is the default request body built from the message
+type messageBody struct {
+	Channel string               `json:"channel"`
+	Color   string               `json:"color"`
+	Title   string               `json:"title"`
+	Body    string               `json:"body"`
+	Fields  []model.MessageField `json:"fields"`
+	Icon    string               `json:"icon"`
+}
+
+func (x *Notifier) Notify(ctx context.Context, msg model.Message) error {
+	req, err := x.buildRequest(ctx, msg)
+	if err != nil {
+		return err
+	}
+
+	if _, err := httpclient.Send(x.client, req); err != nil {
+		// Connecting to local address never succeeds by retry
+		if errors.Is(err, errLocalAddress) {
+			return goerr.Wrap(types.ErrInvalidPolicyOutput, "webhook to local address is not allowed").
+				With("channel", msg.Channel).
+				With("host", req.URL.Host)
+		}
+		return goerr.Wrap(err, "failed to send webhook").With("channel", msg.Channel)
+	}
+
+	return nil
+}
+
+func (x *Notifier) buildRequest(ctx context.Context, msg model.Message) (*http.Request, error) {
+	hook := msg.Webhook
+	if hook == nil {
+		return nil, goerr.Wrap(types.ErrInvalidPolicyOutput, "webhook is required for webhook destination").With("channel", msg.Channel)
+	}
+
+	u, err := url.Parse(hook.URL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, goerr.Wrap(types.ErrInvalidPolicyOutput, "webhook url must be http or https URL").With("channel", msg.Channel)
+	}
+	if !x.hostAllowed(u.Hostname()) {
+		return nil, goerr.Wrap(types.ErrInvalidPolicyOutput, "webhook host is not allowed").With("channel", msg.Channel).With("host", u.Hostname())
+	}
+
+	method := strings.ToUpper(hook.Method)
+	if method == "" {
+		method = http.MethodPost
+	}
+	if _, ok := allowedMethods[method]; !ok {
+		return nil, goerr.Wrap(types.ErrInvalidPolicyOutput, "webhook method is not allowed").With("method", hook.Method)
+	}
+
+	var body []byte
+	contentType := "application/json"
+	switch v := hook.Body.(type) {
+	case string:
+		body = []byte(v)
+		contentType = "text/plain; charset=utf-8"
+	case nil:
+		body, err = json.Marshal(messageBody{
+			Channel: msg.Channel,
+			Color:   msg.Color,
+			Title:   msg.Title,
+			Body:    msg.Body,
+			Fields:  msg.Fields,
+			Icon:    msg.Icon,
+		})
+	default:
+		body, err = json.Marshal(v)
+	}
+	if err != nil {
+		return nil, goerr.Wrap(types.ErrInvalidPolicyOutput.Wrap(err), "failed to encode webhook body")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create webhook request")
+	}
+
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", "nounify")
+	for key, value := range hook.Header {
+		req.Header.Set(key, value)
+	}
+
+	// Signature headers must not be overwritten by the policy
+	if len(x.secret) > 0 {
+		ts := strconv.FormatInt(x.now().Unix(), 10)
+		req.Header.Set(HeaderTimestamp, ts)
+		req.Header.Set(HeaderSignature, "sha256="+Sign(x.secret, ts, body))
+	}
+
+	return req, nil
+}
+
+// Sign returns hex encoded HMAC-SHA256 of "<timestamp>.<body>". Receivers can verify HeaderSignature with it.
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
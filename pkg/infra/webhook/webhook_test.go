@@ -0,0 +1,189 @@
+package webhook_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+	"github.com/m-mizutani/nounify/pkg/domain/types"
+	"github.com/m-mizutani/nounify/pkg/infra/retry"
+	"github.com/m-mizutani/nounify/pkg/infra/webhook"
+	"github.com/m-mizutani/nounify/pkg/utils/testutil"
+)
+
+func TestNotify(t *testing.T) {
+	var (
+		method string
+		header http.Header
+		body   []byte
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		header = r.Header
+		body = gt.R1(io.ReadAll(r.Body)).NoError(t)
+	}))
+	defer srv.Close()
+
+	now := time.Unix(1700000000, 0)
+	notifier := webhook.New(webhook.WithSecret("s3cr3t"), webhook.WithLocalAddressAllowed())
+	notifier.SetNow(func() time.Time { return now })
+
+	t.Run("JSON body with signature", func(t *testing.T) {
+		gt.NoError(t, notifier.Notify(context.Background(), model.Message{
+			Channel: "incident",
+			Webhook: &model.WebhookRequest{
+				URL:    srv.URL + "/hook",
+				Method: "put",
+				Header: map[string]string{
+					"X-Api-Key":             "key",
+					webhook.HeaderSignature: "forged",
+				},
+				Body: map[string]any{"severity": "high"},
+			},
+		}))
+
+		gt.Equal(t, method, http.MethodPut)
+		gt.Equal(t, header.Get("Content-Type"), "application/json")
+		gt.Equal(t, header.Get("X-Api-Key"), "key")
+		gt.Equal(t, string(body), `{"severity":"high"}`)
+		gt.Equal(t, header.Get(webhook.HeaderTimestamp), "1700000000")
+		gt.Equal(t, header.Get(webhook.HeaderSignature), "sha256="+webhook.Sign([]byte("s3cr3t"), "1700000000", body))
+	})
+
+	t.Run("message as default body", func(t *testing.T) {
+		gt.NoError(t, notifier.Notify(context.Background(), model.Message{
+			Channel: "incident",
+			Title:   "Alert",
+			Webhook: &model.WebhookRequest{URL: srv.URL},
+		}))
+
+		gt.Equal(t, method, http.MethodPost)
+		data := testutil.DecodeJSON(t, body).(map[string]any)
+		gt.Equal(t, data["channel"], "incident")
+		gt.Equal(t, data["title"], "Alert")
+	})
+
+	t.Run("string body", func(t *testing.T) {
+		gt.NoError(t, notifier.Notify(context.Background(), model.Message{
+			Webhook: &model.WebhookRequest{
+				URL:    srv.URL,
+				Header: map[string]string{"Content-Type": "text/csv"},
+				Body:   "a,b,c",
+			},
+		}))
+
+		gt.Equal(t, header.Get("Content-Type"), "text/csv")
+		gt.Equal(t, string(body), "a,b,c")
+	})
+}
+
+func TestNotifyInvalid(t *testing.T) {
+	notifier := webhook.New()
+	testCases := map[string]*model.WebhookRequest{
+		"no webhook":     nil,
+		"invalid scheme": {URL: "file:///etc/passwd"},
+		"no host":        {URL: "https://"},
+		"invalid method": {URL: "https://example.com", Method: "DELETE"},
+	}
+	for title, req := range testCases {
+		t.Run(title, func(t *testing.T) {
+			err := notifier.Notify(context.Background(), model.Message{Webhook: req})
+			gt.Error(t, err)
+			retryable, _ := retry.IsTemporary(err)
+			gt.False(t, retryable)
+		})
+	}
+}
+
+func TestNotifyDestination(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer srv.Close()
+
+	notify := func(notifier *webhook.Notifier, url string) error {
+		return notifier.Notify(context.Background(), model.Message{
+			Webhook: &model.WebhookRequest{URL: url},
+		})
+	}
+
+	t.Run("local addresses are rejected by default", func(t *testing.T) {
+		notifier := webhook.New()
+		for _, url := range []string{
+			srv.URL,
+			"http://169.254.169.254/latest/meta-data/",
+			"http://[::1]:8080/admin/dead-letters",
+		} {
+			err := notify(notifier, url)
+			gt.Error(t, err)
+			gt.True(t, errors.Is(err, types.ErrInvalidPolicyOutput))
+			retryable, _ := retry.IsTemporary(err)
+			gt.False(t, retryable)
+		}
+		gt.Equal(t, calls.Load(), int32(0))
+	})
+
+	t.Run("local address can be allowed", func(t *testing.T) {
+		notifier := webhook.New(webhook.WithLocalAddressAllowed())
+		gt.NoError(t, notify(notifier, srv.URL))
+		gt.Equal(t, calls.Load(), int32(1))
+	})
+
+	t.Run("host must be allowed", func(t *testing.T) {
+		notifier := webhook.New(
+			webhook.WithAllowedHosts("127.0.0.1", "*.example.com"),
+			webhook.WithLocalAddressAllowed(),
+		)
+		gt.NoError(t, notify(notifier, srv.URL))
+
+		err := notify(notifier, "https://attacker.example.org/hook")
+		gt.Error(t, err)
+		gt.True(t, errors.Is(err, types.ErrInvalidPolicyOutput))
+		gt.Equal(t, calls.Load(), int32(2))
+	})
+}
+
+func TestNotifyRetry(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer srv.Close()
+
+	cfg := retry.Config{MaxAttempts: 3, Interval: time.Millisecond, MaxInterval: time.Millisecond}
+	notifier := retry.NewNotifier(webhook.New(webhook.WithLocalAddressAllowed()), "webhook", cfg)
+	gt.NoError(t, notifier.Notify(context.Background(), model.Message{
+		Webhook: &model.WebhookRequest{URL: srv.URL},
+	}))
+	gt.Equal(t, calls.Load(), int32(2))
+}
+
+func TestNotifyTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	notifier := webhook.New(
+		webhook.WithHTTPClient(&http.Client{Timeout: 10 * time.Millisecond}),
+		webhook.WithLocalAddressAllowed(),
+	)
+	err := notifier.Notify(context.Background(), model.Message{
+		Webhook: &model.WebhookRequest{URL: srv.URL},
+	})
+	gt.Error(t, err)
+
+	// Timeout of a single request is retried
+	retryable, _ := retry.IsTemporary(err)
+	gt.True(t, retryable)
+}
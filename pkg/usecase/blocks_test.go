@@ -0,0 +1,108 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/nounify/pkg/domain/mock"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+	"github.com/m-mizutani/nounify/pkg/domain/types"
+	"github.com/m-mizutani/nounify/pkg/usecase"
+	"github.com/m-mizutani/nounify/pkg/utils/testutil"
+	"github.com/m-mizutani/opac"
+	"github.com/slack-go/slack"
+)
+
+func TestBlocks(t *testing.T) {
+	blocks := []map[string]any{
+		{
+			"type": "header",
+			"text": map[string]any{"type": "plain_text", "text": "Deploy"},
+		},
+		{
+			"type": "section",
+			"text": map[string]any{"type": "mrkdwn", "text": "*prod* is deployed"},
+		},
+	}
+
+	testCases := map[string]struct {
+		msg         model.Message
+		hasBlocks   bool
+		attachments string
+		err         bool
+	}{
+		"post blocks": {
+			msg:       model.Message{Channel: "deploy", Title: "Deploy", Blocks: blocks},
+			hasBlocks: true,
+		},
+		"wrap blocks with attachment for color": {
+			msg:         model.Message{Channel: "deploy", Title: "Deploy", Color: "info", Blocks: blocks},
+			attachments: "#2EB67D",
+		},
+		"simplified format is still available": {
+			msg:         model.Message{Channel: "deploy", Title: "Deploy"},
+			attachments: "Deploy",
+		},
+		"reject invalid blocks": {
+			msg: model.Message{Channel: "deploy", Title: "Deploy", Blocks: []map[string]any{
+				{"type": "header", "text": map[string]any{"type": "mrkdwn", "text": "Deploy"}},
+			}},
+			err: true,
+		},
+	}
+
+	for title, tc := range testCases {
+		t.Run(title, func(t *testing.T) {
+			mockPolicy := &mock.PolicyMock{
+				QueryFunc: func(ctx context.Context, query string, input, output any, options ...opac.QueryOption) error {
+					testutil.Transcode(t, output, model.MessageQueryOutput{Messages: []model.Message{tc.msg}})
+					return nil
+				},
+			}
+			slackMock := &mock.SlackMock{
+				PostMessageContextFunc: func(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
+					return channelID, "1000.0001", nil
+				},
+			}
+			uc := usecase.New(
+				usecase.WithSlack(slackMock),
+				usecase.WithPolicy(mockPolicy),
+			)
+
+			_, err := uc.HandleMessage(context.Background(), "test", &model.MessageQueryInput{})
+			if tc.err {
+				gt.Error(t, err).Is(types.ErrInvalidPolicyOutput)
+				gt.A(t, slackMock.PostMessageContextCalls()).Length(0)
+				return
+			}
+			gt.NoError(t, err)
+
+			calls := slackMock.PostMessageContextCalls()
+			gt.A(t, calls).Length(1)
+			values := msgValues(t, calls[0].Options)
+			if tc.msg.Blocks != nil {
+				// Title is used as notification text
+				gt.Equal(t, values.Get("text"), "Deploy")
+			} else {
+				gt.Equal(t, values.Get("text"), "")
+			}
+
+			if tc.hasBlocks {
+				var posted []map[string]any
+				gt.NoError(t, json.Unmarshal([]byte(values.Get("blocks")), &posted))
+				gt.A(t, posted).Length(2)
+				gt.Equal(t, posted[1]["type"], any("section"))
+			} else {
+				gt.Equal(t, values.Get("blocks"), "")
+			}
+
+			if tc.attachments != "" {
+				gt.S(t, values.Get("attachments")).Contains(tc.attachments)
+			} else {
+				gt.Equal(t, values.Get("attachments"), "")
+			}
+		})
+	}
+}
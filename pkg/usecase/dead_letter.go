@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+	"github.com/m-mizutani/nounify/pkg/domain/types"
+	"github.com/m-mizutani/nounify/pkg/utils/ctxutil"
+	"github.com/m-mizutani/nounify/pkg/utils/errutil"
+)
+
+// putDeadLetter saves the failed delivery into dead letter store. It returns true if the delivery was saved.
+func (x *UseCases) putDeadLetter(ctx context.Context, d *model.Delivery, cause error) bool {
+	if x.deadLetter == nil {
+		return false
+	}
+
+	dl := &model.DeadLetter{
+		Delivery: *d,
+		Error:    cause.Error(),
+		FailedAt: time.Now().UTC(),
+	}
+	if err := x.deadLetter.Put(ctx, dl); err != nil {
+		errutil.Handle(ctx, "failed to put dead letter", goerr.Wrap(err).With("delivery_id", d.ID))
+		return false
+	}
+
+	ctxutil.Logger(ctx).Warn("message moved to dead letter store", "delivery_id", d.ID, "channel", d.Message.Channel)
+	return true
+}
+
+func (x *UseCases) ListDeadLetters(ctx context.Context) ([]*model.DeadLetter, error) {
+	if x.deadLetter == nil {
+		return nil, goerr.Wrap(types.ErrDeadLetterDisabled)
+	}
+
+	deadLetters, err := x.deadLetter.List(ctx)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list dead letters")
+	}
+	return deadLetters, nil
+}
+
+func (x *UseCases) GetDeadLetter(ctx context.Context, id string) (*model.DeadLetter, error) {
+	if x.deadLetter == nil {
+		return nil, goerr.Wrap(types.ErrDeadLetterDisabled)
+	}
+
+	dl, err := x.deadLetter.Get(ctx, id)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get dead letter").With("id", id)
+	}
+	if dl == nil {
+		return nil, goerr.Wrap(types.ErrNotFound).With("id", id)
+	}
+	return dl, nil
+}
+
+// RedeliverDeadLetter tries to deliver the dead letter again synchronously even in async mode, to return the result to the operator. If it fails again, the dead letter is updated with the new error.
+func (x *UseCases) RedeliverDeadLetter(ctx context.Context, id string) (*model.DeliveryReport, error) {
+	dl, err := x.GetDeadLetter(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &dl.Delivery
+	report := &model.DeliveryReport{
+		Results: []model.DeliveryResult{
+			{Channel: d.Message.Channel, Title: d.Message.Title},
+		},
+	}
+
+	if err := x.deliver(ctx, d, false); err != nil {
+		x.putDeadLetter(ctx, d, err)
+		report.Results[0].Status = types.DeliveryFailed
+		report.Results[0].Error = err.Error()
+		return report, goerr.Wrap(types.ErrDeliveryFailed.Wrap(err)).With("id", id)
+	}
+	report.Results[0].Status = types.DeliverySucceeded
+
+	if err := x.deadLetter.Delete(ctx, id); err != nil {
+		return report, goerr.Wrap(err, "failed to delete redelivered dead letter").With("id", id)
+	}
+
+	return report, nil
+}
+
+func (x *UseCases) DeleteDeadLetter(ctx context.Context, id string) error {
+	if _, err := x.GetDeadLetter(ctx, id); err != nil {
+		return err
+	}
+
+	if err := x.deadLetter.Delete(ctx, id); err != nil {
+		return goerr.Wrap(err, "failed to delete dead letter").With("id", id)
+	}
+	return nil
+}
+
+// PurgeDeadLetters deletes all dead letters and returns the number of deleted ones.
+func (x *UseCases) PurgeDeadLetters(ctx context.Context) (int, error) {
+	deadLetters, err := x.ListDeadLetters(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, dl := range deadLetters {
+		if err := x.deadLetter.Delete(ctx, dl.ID); err != nil {
+			return i, goerr.Wrap(err, "failed to delete dead letter").With("id", dl.ID)
+		}
+	}
+
+	return len(deadLetters), nil
+}
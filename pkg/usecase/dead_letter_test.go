@@ -0,0 +1,118 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/nounify/pkg/domain/mock"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+	"github.com/m-mizutani/nounify/pkg/domain/types"
+	"github.com/m-mizutani/nounify/pkg/infra/file"
+	"github.com/m-mizutani/nounify/pkg/usecase"
+	"github.com/m-mizutani/nounify/pkg/utils/testutil"
+	"github.com/m-mizutani/opac"
+	"github.com/slack-go/slack"
+)
+
+func TestDeadLetter(t *testing.T) {
+	ctx := context.Background()
+	outbox := gt.R1(file.NewOutbox(t.TempDir())).NoError(t)
+	deadLetters := gt.R1(file.NewDeadLetterStore(t.TempDir())).NoError(t)
+
+	mockPolicy := &mock.PolicyMock{
+		QueryFunc: func(ctx context.Context, query string, input, output any, options ...opac.QueryOption) error {
+			testutil.Transcode(t, output, model.MessageQueryOutput{
+				Messages: []model.Message{{Channel: "ch1", Title: "hello"}},
+			})
+			return nil
+		},
+	}
+
+	slackErr := errors.New("service_unavailable")
+	slackMock := &mock.SlackMock{
+		PostMessageContextFunc: func(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
+			if slackErr != nil {
+				return "", "", slackErr
+			}
+			return channelID, "1234.5678", nil
+		},
+	}
+
+	options := []usecase.Option{
+		usecase.WithSlack(slackMock),
+		usecase.WithPolicy(mockPolicy),
+		usecase.WithOutbox(outbox),
+		usecase.WithDeadLetterStore(deadLetters),
+	}
+	uc := usecase.New(options...)
+
+	input := &model.MessageQueryInput{
+		Method: "POST",
+		Path:   "/msg/test",
+		Header: map[string]string{
+			"Authorization":       "Bearer secret-token",
+			"X-Hub-Signature-256": "sha256=xxx",
+			"Content-Type":        "application/json",
+		},
+	}
+	// Failure in sync mode is not a dead letter because the sender retries
+	_, err := uc.HandleMessage(ctx, "test", input)
+	gt.Error(t, err)
+	gt.A(t, gt.R1(uc.ListDeadLetters(ctx)).NoError(t)).Length(0)
+
+	handleInBackground := func(t *testing.T) {
+		async := usecase.New(append(options, usecase.WithAsync(10, 1))...)
+		gt.R1(async.HandleMessage(ctx, "test", input)).NoError(t)
+		gt.NoError(t, async.Close(ctx))
+	}
+	handleInBackground(t)
+
+	// Failed message is moved from outbox to dead letter store
+	gt.A(t, gt.R1(outbox.List(ctx)).NoError(t)).Length(0)
+	list := gt.R1(uc.ListDeadLetters(ctx)).NoError(t)
+	gt.A(t, list).Length(1)
+	gt.Equal(t, list[0].Error, "service_unavailable")
+	gt.Equal(t, list[0].Message.Title, "hello")
+	gt.Equal(t, list[0].Input.Path, "/msg/test")
+
+	// Credentials of the request must not be persisted
+	gt.Equal(t, list[0].Input.Header["Authorization"], "[REDACTED]")
+	gt.Equal(t, list[0].Input.Header["X-Hub-Signature-256"], "[REDACTED]")
+	gt.Equal(t, list[0].Input.Header["Content-Type"], "application/json")
+	gt.Equal(t, input.Header["Authorization"], "Bearer secret-token")
+
+	id := list[0].ID
+
+	t.Run("redelivery fails again", func(t *testing.T) {
+		slackErr = errors.New("internal_error")
+		_, err := uc.RedeliverDeadLetter(ctx, id)
+		gt.Error(t, err)
+
+		dl := gt.R1(uc.GetDeadLetter(ctx, id)).NoError(t)
+		gt.Equal(t, dl.Error, "internal_error")
+	})
+
+	t.Run("redelivery succeeds", func(t *testing.T) {
+		slackErr = nil
+		report := gt.R1(uc.RedeliverDeadLetter(ctx, id)).NoError(t)
+		gt.Equal(t, report.Results[0].Status, types.DeliverySucceeded)
+
+		_, err := uc.GetDeadLetter(ctx, id)
+		gt.Error(t, err)
+		var xErr types.Error
+		gt.True(t, errors.As(err, &xErr))
+		gt.Equal(t, xErr.Code(), types.ErrNotFound.Code())
+	})
+
+	t.Run("purge", func(t *testing.T) {
+		slackErr = errors.New("service_unavailable")
+		handleInBackground(t)
+		handleInBackground(t)
+
+		n := gt.R1(uc.PurgeDeadLetters(ctx)).NoError(t)
+		gt.Equal(t, n, 2)
+		gt.A(t, gt.R1(uc.ListDeadLetters(ctx)).NoError(t)).Length(0)
+	})
+}
@@ -0,0 +1,52 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+	"github.com/m-mizutani/nounify/pkg/domain/types"
+	"github.com/m-mizutani/nounify/pkg/utils/errutil"
+)
+
+// dedupKey returns a key to identify the request. The key from policy is prioritized over delivery ID of the provider. It returns empty string if the request has no key.
+func dedupKey(schema types.Schema, input *model.MessageQueryInput, output *model.MessageQueryOutput) string {
+	prefix := string(schema) + ":"
+
+	switch {
+	case output.DedupKey != "":
+		return prefix + "policy:" + output.DedupKey
+	case input.Auth.GitHub.App != nil && input.Auth.GitHub.App.Delivery != "":
+		return prefix + "github:" + input.Auth.GitHub.App.Delivery
+	case input.Auth.AWS.SNS != nil && input.Auth.AWS.SNS.MessageId != "":
+		return prefix + "sns:" + input.Auth.AWS.SNS.MessageId
+	case input.PubSub != nil && input.PubSub.MessageID != "":
+		return prefix + "pubsub:" + input.PubSub.MessageID
+	default:
+		return ""
+	}
+}
+
+// claimRequest returns false if the request has been handled already
+func (x *UseCases) claimRequest(ctx context.Context, key string) (bool, error) {
+	if x.dedup == nil || key == "" {
+		return true, nil
+	}
+
+	added, err := x.dedup.Add(ctx, key, x.dedupTTL)
+	if err != nil {
+		return false, goerr.Wrap(err, "failed to add dedup key").With("key", key)
+	}
+	return added, nil
+}
+
+// releaseRequest allows redelivery of the request to be handled again
+func (x *UseCases) releaseRequest(ctx context.Context, key string) {
+	if x.dedup == nil || key == "" {
+		return
+	}
+
+	if err := x.dedup.Remove(ctx, key); err != nil {
+		errutil.Handle(ctx, "failed to remove dedup key", goerr.Wrap(err).With("key", key))
+	}
+}
@@ -0,0 +1,128 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/nounify/pkg/domain/mock"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+	"github.com/m-mizutani/nounify/pkg/infra/memory"
+	"github.com/m-mizutani/nounify/pkg/usecase"
+	"github.com/m-mizutani/nounify/pkg/utils/testutil"
+	"github.com/m-mizutani/opac"
+	"github.com/slack-go/slack"
+)
+
+func TestDedup(t *testing.T) {
+	type testCase struct {
+		output   model.MessageQueryOutput
+		inputs   []*model.MessageQueryInput
+		slackErr []error
+		expCalls int
+		expDup   []bool
+	}
+
+	githubInput := func(delivery string) *model.MessageQueryInput {
+		return &model.MessageQueryInput{
+			Auth: model.AuthContext{
+				GitHub: model.GitHubAuth{
+					App: &model.GitHubAppAuth{Delivery: delivery},
+				},
+			},
+		}
+	}
+	snsInput := func(msgID string) *model.MessageQueryInput {
+		return &model.MessageQueryInput{
+			Auth: model.AuthContext{
+				AWS: model.AwsAuth{
+					SNS: &model.AwsSNSAuth{MessageId: msgID},
+				},
+			},
+		}
+	}
+
+	pubsubInput := func(msgID string) *model.MessageQueryInput {
+		return &model.MessageQueryInput{
+			PubSub: &model.InputPubSub{MessageID: msgID},
+		}
+	}
+
+	runTest := func(tc testCase) func(t *testing.T) {
+		return func(t *testing.T) {
+			mockPolicy := &mock.PolicyMock{
+				QueryFunc: func(ctx context.Context, query string, input, output any, options ...opac.QueryOption) error {
+					testutil.Transcode(t, output, tc.output)
+					return nil
+				},
+			}
+			slackMock := &mock.SlackMock{}
+			slackMock.PostMessageContextFunc = func(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
+				n := len(slackMock.PostMessageContextCalls()) - 1
+				if n < len(tc.slackErr) && tc.slackErr[n] != nil {
+					return "", "", tc.slackErr[n]
+				}
+				return channelID, "1234.5678", nil
+			}
+
+			uc := usecase.New(
+				usecase.WithSlack(slackMock),
+				usecase.WithPolicy(mockPolicy),
+				usecase.WithDedup(memory.NewDedupStore(), time.Hour),
+			)
+
+			for i, input := range tc.inputs {
+				report, _ := uc.HandleMessage(context.Background(), "test", input)
+				gt.Equal(t, report.Duplicate, tc.expDup[i])
+			}
+			gt.A(t, slackMock.PostMessageContextCalls()).Length(tc.expCalls)
+		}
+	}
+
+	msgs := []model.Message{{Channel: "ch1"}}
+
+	t.Run("GitHub redelivery", runTest(testCase{
+		output:   model.MessageQueryOutput{Messages: msgs},
+		inputs:   []*model.MessageQueryInput{githubInput("d1"), githubInput("d1"), githubInput("d2")},
+		expCalls: 2,
+		expDup:   []bool{false, true, false},
+	}))
+
+	t.Run("SNS redelivery", runTest(testCase{
+		output:   model.MessageQueryOutput{Messages: msgs},
+		inputs:   []*model.MessageQueryInput{snsInput("m1"), snsInput("m1")},
+		expCalls: 1,
+		expDup:   []bool{false, true},
+	}))
+
+	t.Run("Pub/Sub redelivery", runTest(testCase{
+		output:   model.MessageQueryOutput{Messages: msgs},
+		inputs:   []*model.MessageQueryInput{pubsubInput("p1"), pubsubInput("p1"), pubsubInput("p2")},
+		expCalls: 2,
+		expDup:   []bool{false, true, false},
+	}))
+
+	t.Run("dedup_key from policy is prioritized", runTest(testCase{
+		output:   model.MessageQueryOutput{Messages: msgs, DedupKey: "incident-1"},
+		inputs:   []*model.MessageQueryInput{githubInput("d1"), githubInput("d2")},
+		expCalls: 1,
+		expDup:   []bool{false, true},
+	}))
+
+	t.Run("no key", runTest(testCase{
+		output:   model.MessageQueryOutput{Messages: msgs},
+		inputs:   []*model.MessageQueryInput{{}, {}},
+		expCalls: 2,
+		expDup:   []bool{false, false},
+	}))
+
+	t.Run("redelivery is accepted after failure", runTest(testCase{
+		output:   model.MessageQueryOutput{Messages: msgs},
+		inputs:   []*model.MessageQueryInput{githubInput("d1"), githubInput("d1"), githubInput("d1")},
+		slackErr: []error{errors.New("service_unavailable")},
+		expCalls: 2,
+		expDup:   []bool{false, false, true},
+	}))
+}
@@ -0,0 +1,229 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+	"github.com/m-mizutani/nounify/pkg/domain/types"
+	"github.com/m-mizutani/nounify/pkg/utils/ctxutil"
+	"github.com/m-mizutani/nounify/pkg/utils/errutil"
+)
+
+const (
+	defaultDigestWindow = 5 * time.Minute
+	defaultDigestMax    = 50
+
+	// digestMaxTitles is the maximum number of titles listed in a digest message
+	digestMaxTitles = 20
+)
+
+type digestGroup struct {
+	ctx        context.Context
+	deliveries []*model.Delivery
+	max        int
+	timer      *time.Timer
+}
+
+// digest buffers messages per channel and group, and flushes them as one summarized message.
+type digest struct {
+	groups map[string]*digestGroup
+	mutex  sync.Mutex
+	wg     sync.WaitGroup
+	flush  func(ctx context.Context, deliveries []*model.Delivery)
+}
+
+func newDigest(flush func(ctx context.Context, deliveries []*model.Delivery)) *digest {
+	return &digest{
+		groups: make(map[string]*digestGroup),
+		flush:  flush,
+	}
+}
+
+func digestKey(msg model.Message) string {
+	return string(msg.DestinationOrDefault()) + "\x00" + msg.Workspace + "\x00" + msg.Channel + "\x00" + msg.Group
+}
+
+// applyDigestConfig fills digest settings of the message with settings of the schema
+func applyDigestConfig(msg *model.Message, cfg *model.DigestConfig) {
+	if msg.Group == "" || cfg == nil {
+		return
+	}
+	if msg.DigestWindow == "" {
+		msg.DigestWindow = cfg.Window
+	}
+	if msg.DigestMax == 0 {
+		msg.DigestMax = cfg.Max
+	}
+}
+
+func (x *digest) add(ctx context.Context, d *model.Delivery) error {
+	window := defaultDigestWindow
+	if d.Message.DigestWindow != "" {
+		w, err := time.ParseDuration(d.Message.DigestWindow)
+		if err != nil {
+			return goerr.Wrap(types.ErrInvalidPolicyOutput.Wrap(err), "invalid digest_window").With("digest_window", d.Message.DigestWindow)
+		}
+		window = w
+	}
+	max := defaultDigestMax
+	if d.Message.DigestMax > 0 {
+		max = d.Message.DigestMax
+	}
+
+	key := digestKey(d.Message)
+
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	group, ok := x.groups[key]
+	if !ok {
+		group = &digestGroup{
+			ctx: context.WithoutCancel(ctx),
+			max: max,
+		}
+		group.timer = time.AfterFunc(window, func() { x.flushGroup(key, group) })
+		x.groups[key] = group
+		x.wg.Add(1)
+	}
+	group.deliveries = append(group.deliveries, d)
+
+	// Detach the group right now so that following messages go to a new group
+	if len(group.deliveries) >= group.max && group.timer.Stop() {
+		delete(x.groups, key)
+		go x.flushGroup(key, group)
+	}
+
+	return nil
+}
+
+func (x *digest) flushGroup(key string, group *digestGroup) {
+	defer x.wg.Done()
+
+	x.mutex.Lock()
+	if x.groups[key] == group {
+		delete(x.groups, key)
+	}
+	deliveries := group.deliveries
+	x.mutex.Unlock()
+
+	x.flush(group.ctx, deliveries)
+}
+
+// close flushes all buffered messages immediately and waits for completion
+func (x *digest) close(ctx context.Context) error {
+	x.mutex.Lock()
+	for key, group := range x.groups {
+		if group.timer.Stop() {
+			go x.flushGroup(key, group)
+		}
+	}
+	x.mutex.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		x.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return goerr.Wrap(ctx.Err(), "failed to flush digest messages")
+	}
+}
+
+// buildDigestMessage summarizes buffered messages into one message. A single message is returned as it is.
+func buildDigestMessage(deliveries []*model.Delivery) model.Message {
+	first := deliveries[0]
+	if len(deliveries) == 1 {
+		// Suppression has been applied when buffering. dedup_key is kept because it also identifies an alert of PagerDuty.
+		msg := first.Message
+		msg.Group = ""
+		msg.SuppressFor = ""
+		return msg
+	}
+	last := deliveries[len(deliveries)-1]
+
+	var lines []string
+	for i, d := range deliveries {
+		if i >= digestMaxTitles {
+			lines = append(lines, fmt.Sprintf("…and %d more", len(deliveries)-digestMaxTitles))
+			break
+		}
+		title := d.Message.Title
+		if title == "" {
+			title = "(no title)"
+		}
+		lines = append(lines, "• "+title)
+	}
+
+	return model.Message{
+		Destination: first.Message.Destination,
+		Workspace:   first.Message.Workspace,
+		Channel:     first.Message.Channel,
+		Color:       first.Message.Color,
+		Title:       fmt.Sprintf("%s (%d messages)", first.Message.Group, len(deliveries)),
+		Body:        strings.Join(lines, "\n"),
+		Fields: []model.MessageField{
+			{Name: "Count", Value: fmt.Sprintf("%d", len(deliveries))},
+			{Name: "First", Value: first.CreatedAt.Format(time.RFC3339)},
+			{Name: "Last", Value: last.CreatedAt.Format(time.RFC3339)},
+		},
+		Icon:      first.Message.Icon,
+		Emoji:     first.Message.Emoji,
+		Webhook:   digestWebhook(first.Message.Webhook),
+		PagerDuty: first.Message.PagerDuty,
+	}
+}
+
+// digestWebhook sends the digest message instead of the body of the first message
+func digestWebhook(req *model.WebhookRequest) *model.WebhookRequest {
+	if req == nil {
+		return nil
+	}
+	digestReq := *req
+	digestReq.Body = nil
+	return &digestReq
+}
+
+// flushDigest delivers the digest message in place of buffered messages. Buffered messages are kept in outbox until the digest message is persisted, and they are replayed individually if the process stops before that.
+func (x *UseCases) flushDigest(ctx context.Context, deliveries []*model.Delivery) {
+	first := deliveries[0]
+	msg := buildDigestMessage(deliveries)
+	d := model.NewDelivery(first.Schema, first.Input, msg)
+
+	ctxutil.Logger(ctx).Info("flush digest",
+		"channel", msg.Channel,
+		"group", first.Message.Group,
+		"count", len(deliveries),
+	)
+
+	if err := x.putOutbox(ctx, d); err != nil {
+		errutil.Handle(ctx, "failed to put digest message into outbox", goerr.Wrap(err).With("group", first.Message.Group))
+		return
+	}
+	for _, buffered := range deliveries {
+		x.deleteOutbox(ctx, buffered)
+	}
+
+	// No sender waits for the digest message, then it is kept in outbox for replay if the failure is transient
+	var err error
+	if x.queue != nil {
+		err = x.queue.push(ctx, d)
+	} else {
+		err = x.deliverInBackground(ctx, d)
+	}
+	if err != nil {
+		ctxutil.Logger(ctx).Error("failed to deliver digest message",
+			"channel", msg.Channel,
+			"group", first.Message.Group,
+			"error", err,
+		)
+	}
+}
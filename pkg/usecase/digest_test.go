@@ -0,0 +1,146 @@
+package usecase_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/nounify/pkg/domain/mock"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+	"github.com/m-mizutani/nounify/pkg/domain/types"
+	"github.com/m-mizutani/nounify/pkg/infra/file"
+	"github.com/m-mizutani/nounify/pkg/usecase"
+	"github.com/m-mizutani/nounify/pkg/utils/testutil"
+	"github.com/m-mizutani/opac"
+	"github.com/slack-go/slack"
+)
+
+func TestDigest(t *testing.T) {
+	type testCase struct {
+		outputs    []model.MessageQueryOutput
+		wait       time.Duration
+		expFlushed map[string]int // channel -> number of posts before close
+		expPosts   map[string]int // channel -> number of posts
+	}
+
+	runTest := func(tc testCase) func(t *testing.T) {
+		return func(t *testing.T) {
+			var idx int
+			mockPolicy := &mock.PolicyMock{
+				QueryFunc: func(ctx context.Context, query string, input, output any, options ...opac.QueryOption) error {
+					testutil.Transcode(t, output, tc.outputs[idx])
+					idx++
+					return nil
+				},
+			}
+
+			var mutex sync.Mutex
+			posts := map[string]int{}
+			slackMock := &mock.SlackMock{
+				PostMessageContextFunc: func(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
+					mutex.Lock()
+					defer mutex.Unlock()
+					posts[channelID]++
+					return channelID, "1234.5678", nil
+				},
+			}
+
+			uc := usecase.New(
+				usecase.WithSlack(slackMock),
+				usecase.WithPolicy(mockPolicy),
+			)
+
+			for range tc.outputs {
+				report := gt.R1(uc.HandleMessage(context.Background(), "test", &model.MessageQueryInput{})).NoError(t)
+				for _, r := range report.Results {
+					gt.Equal(t, r.Status, types.DeliveryBuffered)
+				}
+			}
+
+			time.Sleep(tc.wait)
+			mutex.Lock()
+			gt.Equal(t, posts, tc.expFlushed)
+			mutex.Unlock()
+
+			gt.NoError(t, uc.Close(context.Background()))
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			gt.Equal(t, posts, tc.expPosts)
+		}
+	}
+
+	t.Run("flush by window", runTest(testCase{
+		outputs: []model.MessageQueryOutput{
+			{Messages: []model.Message{{Channel: "ch1", Title: "a", Group: "dependabot", DigestWindow: "10ms"}}},
+			{Messages: []model.Message{{Channel: "ch1", Title: "b", Group: "dependabot", DigestWindow: "10ms"}}},
+			{Messages: []model.Message{{Channel: "ch2", Title: "c", Group: "dependabot", DigestWindow: "10ms"}}},
+		},
+		wait:       100 * time.Millisecond,
+		expFlushed: map[string]int{"ch1": 1, "ch2": 1},
+		expPosts:   map[string]int{"ch1": 1, "ch2": 1},
+	}))
+
+	t.Run("flush by max size with schema config", runTest(testCase{
+		outputs: []model.MessageQueryOutput{
+			{
+				Messages: []model.Message{
+					{Channel: "ch1", Title: "a", Group: "gcs"},
+					{Channel: "ch1", Title: "b", Group: "gcs"},
+					{Channel: "ch1", Title: "c", Group: "gcs"},
+				},
+				Digest: &model.DigestConfig{Window: "1h", Max: 2},
+			},
+		},
+		wait:       100 * time.Millisecond,
+		expFlushed: map[string]int{"ch1": 1}, // 2 messages by max size
+		expPosts:   map[string]int{"ch1": 2}, // 1 message by close
+	}))
+
+	t.Run("flush on close", runTest(testCase{
+		outputs: []model.MessageQueryOutput{
+			{Messages: []model.Message{{Channel: "ch1", Title: "a", Group: "gcs", DigestWindow: "1h"}}},
+		},
+		expFlushed: map[string]int{},
+		expPosts:   map[string]int{"ch1": 1},
+	}))
+}
+
+func TestDigestOutbox(t *testing.T) {
+	ctx := context.Background()
+	outbox := gt.R1(file.NewOutbox(t.TempDir())).NoError(t)
+
+	mockPolicy := &mock.PolicyMock{
+		QueryFunc: func(ctx context.Context, query string, input, output any, options ...opac.QueryOption) error {
+			testutil.Transcode(t, output, model.MessageQueryOutput{
+				Messages: []model.Message{
+					{Channel: "ch1", Title: "a", Group: "gcs", DigestWindow: "1h"},
+					{Channel: "ch1", Title: "b", Group: "gcs", DigestWindow: "1h"},
+				},
+			})
+			return nil
+		},
+	}
+	slackMock := &mock.SlackMock{
+		PostMessageContextFunc: func(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
+			return channelID, "1234.5678", nil
+		},
+	}
+
+	uc := usecase.New(
+		usecase.WithSlack(slackMock),
+		usecase.WithPolicy(mockPolicy),
+		usecase.WithOutbox(outbox),
+	)
+
+	gt.R1(uc.HandleMessage(ctx, "test", &model.MessageQueryInput{})).NoError(t)
+
+	// Buffered messages are persisted to be replayed after crash
+	gt.A(t, gt.R1(outbox.List(ctx)).NoError(t)).Length(2)
+
+	gt.NoError(t, uc.Close(ctx))
+	gt.A(t, slackMock.PostMessageContextCalls()).Length(1)
+	gt.A(t, gt.R1(outbox.List(ctx)).NoError(t)).Length(0)
+}
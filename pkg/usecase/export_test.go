@@ -0,0 +1,8 @@
+package usecase
+
+// LockedKeys returns number of keys in keyLock
+func (x *UseCases) LockedKeys() int {
+	x.keyLock.mutex.Lock()
+	defer x.keyLock.mutex.Unlock()
+	return len(x.keyLock.locks)
+}
@@ -0,0 +1,71 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/nounify/pkg/domain/interfaces"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+	"github.com/m-mizutani/nounify/pkg/domain/types"
+	"github.com/m-mizutani/nounify/pkg/utils/ctxutil"
+	"github.com/m-mizutani/nounify/pkg/utils/errutil"
+	"github.com/slack-go/slack"
+)
+
+const (
+	// maxFiles is the maximum number of files uploaded with a message
+	maxFiles = 10
+	// maxFileSize is the maximum size of a file. Files are kept in memory and outbox until delivered.
+	maxFileSize = 1024 * 1024
+)
+
+func validateFiles(files []model.MessageFile) error {
+	if len(files) > maxFiles {
+		return goerr.Wrap(types.ErrInvalidPolicyOutput, "too many files").With("files", len(files)).With("max", maxFiles)
+	}
+
+	for i, file := range files {
+		if file.Name == "" {
+			return goerr.Wrap(types.ErrInvalidPolicyOutput, "name of file is required").With("index", i)
+		}
+		if file.Content == "" {
+			return goerr.Wrap(types.ErrInvalidPolicyOutput, "content of file is required").With("index", i).With("name", file.Name)
+		}
+		if len(file.Content) > maxFileSize {
+			return goerr.Wrap(types.ErrInvalidPolicyOutput, "file is too large").
+				With("index", i).
+				With("name", file.Name).
+				With("size", len(file.Content)).
+				With("max", maxFileSize)
+		}
+	}
+
+	return nil
+}
+
+// uploadFiles uploads files of the message to the channel, or to the thread if threadTS is set. A failed upload does not fail the delivery because the message has been posted and redelivery would post it twice.
+func (x *UseCases) uploadFiles(ctx context.Context, client interfaces.Slack, msg model.Message, channelID, threadTS string) {
+	for _, file := range msg.Files {
+		title := file.Title
+		if title == "" {
+			title = file.Name
+		}
+
+		summary, err := client.UploadFileV2Context(ctx, slack.UploadFileV2Parameters{
+			Filename:        file.Name,
+			Title:           title,
+			Content:         file.Content,
+			FileSize:        len(file.Content),
+			Channel:         channelID,
+			ThreadTimestamp: threadTS,
+		})
+		if err != nil {
+			errutil.Handle(ctx, "failed to upload file", goerr.Wrap(err).
+				With("channel", msg.Channel).
+				With("name", file.Name))
+			continue
+		}
+
+		ctxutil.Logger(ctx).Debug("uploaded file", "name", file.Name, "file_id", summary.ID, "thread_ts", threadTS)
+	}
+}
@@ -0,0 +1,129 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/nounify/pkg/domain/mock"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+	"github.com/m-mizutani/nounify/pkg/domain/types"
+	"github.com/m-mizutani/nounify/pkg/infra/memory"
+	"github.com/m-mizutani/nounify/pkg/usecase"
+	"github.com/m-mizutani/nounify/pkg/utils/testutil"
+	"github.com/m-mizutani/opac"
+	"github.com/slack-go/slack"
+)
+
+func TestUploadFiles(t *testing.T) {
+	var messages []model.Message
+	mockPolicy := &mock.PolicyMock{
+		QueryFunc: func(ctx context.Context, query string, input, output any, options ...opac.QueryOption) error {
+			testutil.Transcode(t, output, model.MessageQueryOutput{Messages: messages})
+			return nil
+		},
+	}
+
+	var uploadErr error
+	slackMock := &mock.SlackMock{}
+	slackMock.PostMessageContextFunc = func(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
+		n := len(slackMock.PostMessageContextCalls())
+		// Replies are posted to the channel ID of the parent
+		return "C_" + strings.TrimPrefix(channelID, "C_"), fmt.Sprintf("1000.%04d", n), nil
+	}
+	slackMock.UploadFileV2ContextFunc = func(ctx context.Context, params slack.UploadFileV2Parameters) (*slack.FileSummary, error) {
+		if uploadErr != nil {
+			return nil, uploadErr
+		}
+		return &slack.FileSummary{ID: "F0001"}, nil
+	}
+
+	uc := usecase.New(
+		usecase.WithSlack(slackMock),
+		usecase.WithPolicy(mockPolicy),
+		usecase.WithMessageRefStore(memory.NewMessageRefStore()),
+	)
+	ctx := context.Background()
+	plan := model.MessageFile{Name: "plan.txt", Content: "+ resource \"aws_s3_bucket\" \"logs\""}
+
+	t.Run("upload files to the channel of the message", func(t *testing.T) {
+		messages = []model.Message{
+			{Channel: "infra", Title: "terraform plan", Files: []model.MessageFile{plan}},
+		}
+		gt.R1(uc.HandleMessage(ctx, "test", &model.MessageQueryInput{})).NoError(t)
+
+		calls := slackMock.UploadFileV2ContextCalls()
+		gt.A(t, calls).Length(1)
+		gt.Equal(t, calls[0].Params.Channel, "C_infra")
+		gt.Equal(t, calls[0].Params.ThreadTimestamp, "")
+		gt.Equal(t, calls[0].Params.Filename, "plan.txt")
+		gt.Equal(t, calls[0].Params.Title, "plan.txt")
+		gt.Equal(t, calls[0].Params.Content, plan.Content)
+		gt.Equal(t, calls[0].Params.FileSize, len(plan.Content))
+	})
+
+	t.Run("upload files to the thread of the message", func(t *testing.T) {
+		messages = []model.Message{
+			{Channel: "infra", Title: "parent", ThreadKey: "pr-1"},
+		}
+		gt.R1(uc.HandleMessage(ctx, "test", &model.MessageQueryInput{})).NoError(t)
+		messages = []model.Message{
+			{Channel: "infra", Title: "reply", ThreadKey: "pr-1", Files: []model.MessageFile{plan}},
+		}
+		gt.R1(uc.HandleMessage(ctx, "test", &model.MessageQueryInput{})).NoError(t)
+
+		calls := slackMock.UploadFileV2ContextCalls()
+		gt.A(t, calls).Length(2)
+		gt.Equal(t, calls[1].Params.Channel, "C_infra")
+		gt.Equal(t, calls[1].Params.ThreadTimestamp, "1000.0002")
+	})
+
+	t.Run("failed upload does not fail the delivery", func(t *testing.T) {
+		uploadErr = errors.New("upload failed")
+		messages = []model.Message{
+			{Channel: "infra", Title: "terraform plan", Files: []model.MessageFile{plan}},
+		}
+		report := gt.R1(uc.HandleMessage(ctx, "test", &model.MessageQueryInput{})).NoError(t)
+		gt.Equal(t, report.Results[0].Status, types.DeliverySucceeded)
+	})
+}
+
+func TestUploadFilesInvalid(t *testing.T) {
+	testCases := map[string][]model.MessageFile{
+		"no name":    {{Content: "data"}},
+		"no content": {{Name: "empty.txt"}},
+		"too large":  {{Name: "large.txt", Content: strings.Repeat("a", 1024*1024+1)}},
+		"too many files": func() []model.MessageFile {
+			files := make([]model.MessageFile, 11)
+			for i := range files {
+				files[i] = model.MessageFile{Name: fmt.Sprintf("%d.txt", i), Content: "data"}
+			}
+			return files
+		}(),
+	}
+
+	for title, files := range testCases {
+		t.Run(title, func(t *testing.T) {
+			mockPolicy := &mock.PolicyMock{
+				QueryFunc: func(ctx context.Context, query string, input, output any, options ...opac.QueryOption) error {
+					testutil.Transcode(t, output, model.MessageQueryOutput{Messages: []model.Message{
+						{Channel: "infra", Title: "terraform plan", Files: files},
+					}})
+					return nil
+				},
+			}
+			slackMock := &mock.SlackMock{}
+			uc := usecase.New(
+				usecase.WithSlack(slackMock),
+				usecase.WithPolicy(mockPolicy),
+			)
+
+			_, err := uc.HandleMessage(context.Background(), "test", &model.MessageQueryInput{})
+			gt.Error(t, err).Is(types.ErrInvalidPolicyOutput)
+			gt.A(t, slackMock.PostMessageContextCalls()).Length(0)
+		})
+	}
+}
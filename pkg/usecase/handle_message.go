@@ -2,87 +2,239 @@ package usecase
 
 import (
 	"context"
-	"fmt"
+	"errors"
 
 	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/nounify/pkg/domain/interfaces"
 	"github.com/m-mizutani/nounify/pkg/domain/model"
 	"github.com/m-mizutani/nounify/pkg/domain/types"
 	"github.com/m-mizutani/nounify/pkg/utils/ctxutil"
-	"github.com/slack-go/slack"
+	"github.com/m-mizutani/nounify/pkg/utils/slackmsg"
 )
 
-func (x *UseCases) HandleMessage(ctx context.Context, schema types.Schema, input *model.MessageQueryInput) error {
+func (x *UseCases) HandleMessage(ctx context.Context, schema types.Schema, input *model.MessageQueryInput) (*model.DeliveryReport, error) {
 
 	var output model.MessageQueryOutput
 	if err := x.policy.Query(ctx, schema.ToQuery(), input, &output); err != nil {
-		return goerr.Wrap(err).With("query", schema.ToQuery()).With("input", input)
+		return nil, goerr.Wrap(err).With("query", schema.ToQuery()).With("input", input)
 	}
 	ctxutil.Logger(ctx).Info("msg query result", "input", input, "output", output)
 
-	for _, msg := range output.Messages {
-		attachment := buildSlackMessage(msg)
-		options := []slack.MsgOption{
-			slack.MsgOptionAttachments(attachment),
+	key := dedupKey(schema, input, &output)
+	if ok, err := x.claimRequest(ctx, key); err != nil {
+		return nil, err
+	} else if !ok {
+		ctxutil.Logger(ctx).Info("duplicated request, skip delivery", "dedup_key", key)
+		return &model.DeliveryReport{Duplicate: true}, nil
+	}
+
+	// Every message is delivered independently. A failure of one message does not prevent delivery of the others.
+	report := &model.DeliveryReport{
+		Results: make([]model.DeliveryResult, len(output.Messages)),
+	}
+	var errs []error
+	for i, msg := range output.Messages {
+		applyDigestConfig(&msg, output.Digest)
+		d := model.NewDelivery(schema, input, msg)
+		report.Results[i] = model.DeliveryResult{
+			Destination: msg.DestinationOrDefault(),
+			Workspace:   msg.Workspace,
+			Channel:     msg.Channel,
+			Title:       msg.Title,
 		}
 
-		if msg.Emoji != "" { // Emoji has higher priority than Icon
-			options = append(options, slack.MsgOptionIconEmoji(msg.Emoji))
-		} else if msg.Icon != "" {
-			options = append(options, slack.MsgOptionIconURL(msg.Icon))
+		status, err := x.dispatch(ctx, d)
+		report.Results[i].Status = status
+		if err != nil {
+			ctxutil.Logger(ctx).Warn("failed to deliver message",
+				"destination", msg.DestinationOrDefault(),
+				"workspace", msg.Workspace,
+				"channel", msg.Channel,
+				"error", err,
+			)
+			report.Results[i].Error = err.Error()
+			errs = append(errs, goerr.Wrap(err, "failed to deliver message").
+				With("destination", msg.DestinationOrDefault()).
+				With("workspace", msg.Workspace).
+				With("channel", msg.Channel).
+				With("msg", msg))
 		}
+	}
 
-		if _, _, err := x.slack.PostMessageContext(ctx, msg.Channel, options...); err != nil {
-			return goerr.Wrap(err).With("msg", msg)
+	if len(errs) > 0 {
+		// Allow redelivery by the sender only if no message has been delivered, to avoid posting the same message twice
+		if len(errs) == len(output.Messages) {
+			x.releaseRequest(ctx, key)
 		}
+
+		return report, goerr.Wrap(types.ErrDeliveryFailed.Wrap(errors.Join(errs...))).
+			With("schema", schema).
+			With("failed", len(errs)).
+			With("total", len(output.Messages))
 	}
 
-	return nil
+	return report, nil
 }
 
-var preservedColors = map[string]string{
-	"info":    "#2EB67D",
-	"warning": "#FFA500",
-	"error":   "#FF0000",
+// validateSlackMessage rejects invalid blocks and files before buffering or queueing because they never succeed by retry
+func validateSlackMessage(msg model.Message) error {
+	if msg.DestinationOrDefault() != types.DestinationSlack {
+		return nil
+	}
+
+	if len(msg.Blocks) > 0 {
+		if _, err := slackmsg.Blocks(msg.Blocks); err != nil {
+			return err
+		}
+	}
+
+	return validateFiles(msg.Files)
 }
 
-func buildSlackMessage(msg model.Message) slack.Attachment {
-	color := "#2EB67D"
-	if msg.Color != "" {
-		if preserved, ok := preservedColors[msg.Color]; ok {
-			color = preserved
-		} else {
-			color = msg.Color
+// dispatch delivers the message immediately, or puts it into the queue in async mode
+func (x *UseCases) dispatch(ctx context.Context, d *model.Delivery) (types.DeliveryStatus, error) {
+	if err := validateSlackMessage(d.Message); err != nil {
+		return types.DeliveryFailed, err
+	}
+
+	if suppressed, err := x.suppress(ctx, d.Message); err != nil {
+		return types.DeliveryFailed, err
+	} else if suppressed {
+		return types.DeliverySuppressed, nil
+	}
+
+	// The message must be persisted before delivery to keep at-least-once delivery semantics
+	if err := x.putOutbox(ctx, d); err != nil {
+		x.releaseSuppression(ctx, d.Message)
+		return types.DeliveryFailed, err
+	}
+
+	if d.Message.Group != "" {
+		if err := x.digest.add(ctx, d); err != nil {
+			x.deleteOutbox(ctx, d)
+			x.releaseSuppression(ctx, d.Message)
+			return types.DeliveryFailed, err
 		}
+		return types.DeliveryBuffered, nil
 	}
 
-	var blockSet []slack.Block
+	if x.queue != nil {
+		if err := x.queue.push(ctx, d); err != nil {
+			// The message was not accepted, then the sender is responsible for retry
+			x.deleteOutbox(ctx, d)
+			x.releaseSuppression(ctx, d.Message)
+			return types.DeliveryFailed, err
+		}
+		return types.DeliveryQueued, nil
+	}
 
-	if msg.Title != "" {
-		txt := slack.NewTextBlockObject("plain_text", msg.Title, false, false)
-		blockSet = append(blockSet, slack.NewHeaderBlock(txt))
+	// The sender receives the error and retries, then the message must not be replayed from outbox
+	if err := x.deliver(ctx, d, false); err != nil {
+		return types.DeliveryFailed, err
 	}
+	return types.DeliverySucceeded, nil
+}
+
+// deliverInBackground delivers the message that no sender waits for, e.g. queued or replayed one. A transient failure is kept in outbox to be replayed at next startup.
+func (x *UseCases) deliverInBackground(ctx context.Context, d *model.Delivery) error {
+	return x.deliver(ctx, d, true)
+}
+
+// deliver posts the message and removes it from outbox. If replayable is true and the failure is transient, the message is kept in outbox.
+func (x *UseCases) deliver(ctx context.Context, d *model.Delivery, replayable bool) error {
+	ctx = ctxutil.WithLogger(ctx, ctxutil.Logger(ctx).With("delivery_id", d.ID))
+
+	if err := x.postMessage(ctx, d.Message); err != nil {
+		x.releaseSuppression(ctx, d.Message)
+
+		// In sync mode, the error is returned to the sender and the sender retries. It is not a dead letter because the retry may deliver it.
+		if !replayable {
+			x.deleteOutbox(ctx, d)
+			return err
+		}
 
-	var body *slack.TextBlockObject
-	if msg.Body != "" {
-		body = slack.NewTextBlockObject("mrkdwn", msg.Body, false, false)
+		// Once saved as dead letter, the message should not be replayed from outbox. A permanent failure (e.g. channel_not_found) never succeeds by replay.
+		if x.putDeadLetter(ctx, d, err) || !isTransient(err) {
+			x.deleteOutbox(ctx, d)
+		}
+		return err
 	}
+	ctxutil.Logger(ctx).Debug("message delivered", "channel", d.Message.Channel)
+
+	x.deleteOutbox(ctx, d)
+	return nil
+}
 
-	fields := make([]*slack.TextBlockObject, len(msg.Fields))
-	for i, field := range msg.Fields {
-		value := field.Value
-		if field.Link != "" {
-			value = "<" + field.Link + "|" + field.Value + ">"
+func (x *UseCases) postMessage(ctx context.Context, msg model.Message) error {
+	if dest := msg.DestinationOrDefault(); dest != types.DestinationSlack {
+		return x.notify(ctx, dest, msg)
+	}
+
+	client, err := x.slackClient(msg)
+	if err != nil {
+		return err
+	}
+
+	// Slack incoming webhook mode without OAuth token
+	if client == nil {
+		if msg.ThreadKey != "" || msg.UpdateKey != "" || len(msg.Files) > 0 {
+			ctxutil.Logger(ctx).Warn("thread_key, update_key and files require Slack OAuth token, ignore them",
+				"thread_key", msg.ThreadKey,
+				"update_key", msg.UpdateKey,
+				"files", len(msg.Files),
+			)
 		}
-		mrkdwn := fmt.Sprintf("*%s*\n%s", field.Name, value)
-		fields[i] = slack.NewTextBlockObject("mrkdwn", mrkdwn, false, false)
+		return x.notify(ctx, types.DestinationSlack, msg)
 	}
 
-	blockSet = append(blockSet, slack.NewSectionBlock(body, fields, nil))
+	if msg.UpdateKey != "" {
+		return x.updateMessage(ctx, client, msg)
+	}
 
-	return slack.Attachment{
-		Color: color,
-		Blocks: slack.Blocks{
-			BlockSet: blockSet,
-		},
+	if _, _, err := x.sendMessage(ctx, client, msg); err != nil {
+		return err
 	}
+
+	return nil
+}
+
+// slackClient returns Slack client of the workspace of the message. It returns nil for the default workspace in incoming webhook mode.
+func (x *UseCases) slackClient(msg model.Message) (interfaces.Slack, error) {
+	if msg.Workspace == "" {
+		if x.slack == nil {
+			if _, ok := x.notifiers[types.DestinationSlack]; !ok {
+				// Only additional workspaces are configured
+				return nil, goerr.Wrap(types.ErrInvalidPolicyOutput, "default Slack workspace is not configured, workspace is required").With("channel", msg.Channel)
+			}
+		}
+		return x.slack, nil
+	}
+
+	client, ok := x.workspaces[msg.Workspace]
+	if !ok {
+		return nil, goerr.Wrap(types.ErrInvalidPolicyOutput, "Slack workspace is not configured").With("workspace", msg.Workspace)
+	}
+	return client, nil
+}
+
+// sendMessage posts a new message with files and returns the channel ID and timestamp of it
+func (x *UseCases) sendMessage(ctx context.Context, client interfaces.Slack, msg model.Message) (string, string, error) {
+	options, err := slackmsg.MsgOptions(msg)
+	if err != nil {
+		return "", "", err
+	}
+
+	var channelID, ts, threadTS string
+	if msg.ThreadKey != "" {
+		channelID, ts, threadTS, err = x.postThreadMessage(ctx, client, msg, options)
+	} else {
+		channelID, ts, err = client.PostMessageContext(ctx, msg.Channel, options...)
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	x.uploadFiles(ctx, client, msg, channelID, threadTS)
+
+	return channelID, ts, nil
 }
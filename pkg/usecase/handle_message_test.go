@@ -3,6 +3,7 @@ package usecase_test
 import (
 	"context"
 	_ "embed"
+	"errors"
 	"testing"
 
 	"github.com/m-mizutani/gt"
@@ -55,7 +56,7 @@ func TestSendSlackMessage(t *testing.T) {
 		Method: "POST",
 		Body:   pubsubCloudStorageData,
 	}
-	gt.NoError(t, uc.HandleMessage(ctx, "cloud_storage", input))
+	gt.R1(uc.HandleMessage(ctx, "cloud_storage", input)).NoError(t)
 	gt.A(t, mockPolicy.QueryCalls()).Length(1)
 }
 
@@ -101,3 +102,84 @@ func TestHandleMessage(t *testing.T) {
 		},
 	})
 }
+
+func TestHandleMessagePartialFailure(t *testing.T) {
+	mockPolicy := &mock.PolicyMock{
+		QueryFunc: func(ctx context.Context, query string, input, output any, options ...opac.QueryOption) error {
+			testutil.Transcode(t, output, model.MessageQueryOutput{
+				Messages: []model.Message{
+					{Channel: "ch1", Title: "first"},
+					{Channel: "ch2", Title: "second"},
+					{Channel: "ch3", Title: "third"},
+				},
+			})
+			return nil
+		},
+	}
+	slackMock := &mock.SlackMock{
+		PostMessageContextFunc: func(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
+			if channelID == "ch2" {
+				return "", "", errors.New("channel_not_found")
+			}
+			return channelID, "1234.5678", nil
+		},
+	}
+
+	uc := usecase.New(
+		usecase.WithSlack(slackMock),
+		usecase.WithPolicy(mockPolicy),
+	)
+
+	report, err := uc.HandleMessage(context.Background(), "test", &model.MessageQueryInput{})
+	gt.Error(t, err)
+	var xErr types.Error
+	gt.True(t, errors.As(err, &xErr))
+	gt.Equal(t, xErr.Code(), types.ErrDeliveryFailed.Code())
+	gt.A(t, slackMock.PostMessageContextCalls()).Length(3)
+
+	gt.Equal(t, report.Results, []model.DeliveryResult{
+		{Destination: types.DestinationSlack, Channel: "ch1", Title: "first", Status: types.DeliverySucceeded},
+		{Destination: types.DestinationSlack, Channel: "ch2", Title: "second", Status: types.DeliveryFailed, Error: "channel_not_found"},
+		{Destination: types.DestinationSlack, Channel: "ch3", Title: "third", Status: types.DeliverySucceeded},
+	})
+}
+
+func TestHandleMessageAsync(t *testing.T) {
+	mockPolicy := &mock.PolicyMock{
+		QueryFunc: func(ctx context.Context, query string, input, output any, options ...opac.QueryOption) error {
+			testutil.Transcode(t, output, model.MessageQueryOutput{
+				Messages: []model.Message{
+					{Channel: "ch1", Title: "first"},
+					{Channel: "ch2", Title: "second"},
+				},
+			})
+			return nil
+		},
+	}
+	slackMock := &mock.SlackMock{
+		PostMessageContextFunc: func(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
+			return channelID, "1234.5678", nil
+		},
+	}
+
+	uc := usecase.New(
+		usecase.WithSlack(slackMock),
+		usecase.WithPolicy(mockPolicy),
+		usecase.WithAsync(10, 2),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	report := gt.R1(uc.HandleMessage(ctx, "test", &model.MessageQueryInput{})).NoError(t)
+	gt.Equal(t, report.Count(types.DeliveryQueued), 2)
+
+	// Cancellation of the request must not abort queued deliveries
+	cancel()
+
+	gt.NoError(t, uc.Close(context.Background()))
+	gt.A(t, slackMock.PostMessageContextCalls()).Length(2)
+
+	// Queue does not accept messages after closing
+	report, err := uc.HandleMessage(context.Background(), "test", &model.MessageQueryInput{})
+	gt.Error(t, err)
+	gt.Equal(t, report.Count(types.DeliveryFailed), 2)
+}
@@ -0,0 +1,23 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+	"github.com/m-mizutani/nounify/pkg/domain/types"
+)
+
+// notify delivers the message by the notifier of the destination
+func (x *UseCases) notify(ctx context.Context, dest types.Destination, msg model.Message) error {
+	notifier, ok := x.notifiers[dest]
+	if !ok {
+		return goerr.Wrap(types.ErrInvalidPolicyOutput, "destination is not configured").With("destination", dest)
+	}
+
+	if err := notifier.Notify(ctx, msg); err != nil {
+		return goerr.Wrap(err, "failed to notify").With("destination", dest)
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/nounify/pkg/domain/mock"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+	"github.com/m-mizutani/nounify/pkg/domain/types"
+	"github.com/m-mizutani/nounify/pkg/usecase"
+	"github.com/m-mizutani/nounify/pkg/utils/testutil"
+	"github.com/m-mizutani/opac"
+	"github.com/slack-go/slack"
+)
+
+func TestNotifierDestination(t *testing.T) {
+	mockPolicy := &mock.PolicyMock{
+		QueryFunc: func(ctx context.Context, query string, input, output any, options ...opac.QueryOption) error {
+			testutil.Transcode(t, output, model.MessageQueryOutput{
+				Messages: []model.Message{
+					{Channel: "ch1", Title: "to slack"},
+					{Destination: types.DestinationTeams, Channel: "ops", Title: "to teams"},
+					{Destination: "unknown", Channel: "ops", Title: "to nowhere"},
+				},
+			})
+			return nil
+		},
+	}
+
+	slackMock := &mock.SlackMock{
+		PostMessageContextFunc: func(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
+			return "", "", nil
+		},
+	}
+	teamsMock := &mock.NotifierMock{
+		NotifyFunc: func(ctx context.Context, msg model.Message) error {
+			return nil
+		},
+	}
+
+	uc := usecase.New(
+		usecase.WithSlack(slackMock),
+		usecase.WithPolicy(mockPolicy),
+		usecase.WithNotifier(types.DestinationTeams, teamsMock),
+	)
+
+	report, err := uc.HandleMessage(context.Background(), "test", &model.MessageQueryInput{})
+	gt.Error(t, err)
+
+	gt.A(t, slackMock.PostMessageContextCalls()).Length(1)
+	gt.A(t, teamsMock.NotifyCalls()).Length(1)
+	gt.Equal(t, teamsMock.NotifyCalls()[0].Msg.Title, "to teams")
+
+	gt.Equal(t, report.Results[0].Destination, types.DestinationSlack)
+	gt.Equal(t, report.Results[0].Status, types.DeliverySucceeded)
+	gt.Equal(t, report.Results[1].Destination, types.DestinationTeams)
+	gt.Equal(t, report.Results[1].Status, types.DeliverySucceeded)
+	gt.Equal(t, report.Results[2].Status, types.DeliveryFailed)
+}
+
+func TestSlackWebhookMode(t *testing.T) {
+	mockPolicy := &mock.PolicyMock{
+		QueryFunc: func(ctx context.Context, query string, input, output any, options ...opac.QueryOption) error {
+			testutil.Transcode(t, output, model.MessageQueryOutput{
+				Messages: []model.Message{
+					{Channel: "alerts", Title: "hello", ThreadKey: "t1"},
+				},
+			})
+			return nil
+		},
+	}
+	webhookMock := &mock.NotifierMock{
+		NotifyFunc: func(ctx context.Context, msg model.Message) error {
+			return nil
+		},
+	}
+
+	uc := usecase.New(
+		usecase.WithPolicy(mockPolicy),
+		usecase.WithNotifier(types.DestinationSlack, webhookMock),
+	)
+
+	report := gt.R1(uc.HandleMessage(context.Background(), "test", &model.MessageQueryInput{})).NoError(t)
+	gt.Equal(t, report.Results[0].Status, types.DeliverySucceeded)
+	gt.A(t, webhookMock.NotifyCalls()).Length(1)
+	gt.Equal(t, webhookMock.NotifyCalls()[0].Msg.Channel, "alerts")
+}
@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+	"github.com/m-mizutani/nounify/pkg/domain/types"
+	"github.com/m-mizutani/nounify/pkg/infra/retry"
+	"github.com/m-mizutani/nounify/pkg/utils/ctxutil"
+	"github.com/m-mizutani/nounify/pkg/utils/errutil"
+)
+
+func (x *UseCases) putOutbox(ctx context.Context, d *model.Delivery) error {
+	if x.outbox == nil {
+		return nil
+	}
+
+	if err := x.outbox.Put(ctx, d); err != nil {
+		return goerr.Wrap(err, "failed to put message into outbox").With("delivery_id", d.ID)
+	}
+	return nil
+}
+
+func (x *UseCases) deleteOutbox(ctx context.Context, d *model.Delivery) {
+	if x.outbox == nil {
+		return
+	}
+
+	if err := x.outbox.Delete(ctx, d.ID); err != nil {
+		// If the message has been delivered already, it will be delivered again when replaying outbox.
+		errutil.Handle(ctx, "failed to delete message from outbox", goerr.Wrap(err).With("delivery_id", d.ID))
+	}
+}
+
+// isTransient returns true if err may be resolved by delivering the message again later. Errors of notifiers are classified by retry.Temporary mark.
+func isTransient(err error) bool {
+	transient, _ := retry.IsSlackTemporary(err)
+	return transient
+}
+
+// ReplayOutbox delivers pending messages remaining in outbox, e.g. messages in flight when the previous process stopped. It must complete before accepting new requests, otherwise messages being delivered by them are delivered twice.
+func (x *UseCases) ReplayOutbox(ctx context.Context) error {
+	if x.outbox == nil {
+		return nil
+	}
+
+	deliveries, err := x.outbox.List(ctx)
+	if err != nil {
+		return goerr.Wrap(err, "failed to list outbox")
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	ctxutil.Logger(ctx).Info("replaying outbox", "count", len(deliveries))
+
+	var errs []error
+	for _, d := range deliveries {
+		if x.queue != nil {
+			err := x.queue.push(ctx, d)
+			if err == nil {
+				continue
+			}
+			// Backlog larger than the queue is delivered inline, because replay completes before accepting requests
+			if !errors.Is(err, types.ErrQueueFull) {
+				// Keep it in outbox to retry at next startup
+				errs = append(errs, goerr.Wrap(err, "failed to queue message from outbox").With("delivery_id", d.ID))
+				continue
+			}
+		}
+
+		if err := x.deliverInBackground(ctx, d); err != nil {
+			errs = append(errs, goerr.Wrap(err, "failed to deliver message from outbox").With("delivery_id", d.ID))
+		}
+	}
+
+	if len(errs) > 0 {
+		return goerr.Wrap(types.ErrDeliveryFailed.Wrap(errors.Join(errs...))).
+			With("failed", len(errs)).
+			With("total", len(deliveries))
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/nounify/pkg/domain/mock"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+	"github.com/m-mizutani/nounify/pkg/infra/file"
+	"github.com/m-mizutani/nounify/pkg/usecase"
+	"github.com/m-mizutani/nounify/pkg/utils/testutil"
+	"github.com/m-mizutani/opac"
+	"github.com/slack-go/slack"
+)
+
+func TestOutbox(t *testing.T) {
+	ctx := context.Background()
+	outbox := gt.R1(file.NewOutbox(t.TempDir())).NoError(t)
+
+	mockPolicy := &mock.PolicyMock{
+		QueryFunc: func(ctx context.Context, query string, input, output any, options ...opac.QueryOption) error {
+			testutil.Transcode(t, output, model.MessageQueryOutput{
+				Messages: []model.Message{
+					{Channel: "ok"},
+					{Channel: "ng"},
+					{Channel: "gone"},
+				},
+			})
+			return nil
+		},
+	}
+
+	var slackDown bool
+	slackMock := &mock.SlackMock{
+		PostMessageContextFunc: func(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
+			// Message must be persisted before posting
+			list := gt.R1(outbox.List(ctx)).NoError(t)
+			gt.A(t, list).Longer(0)
+
+			switch {
+			case channelID == "gone":
+				return "", "", slack.SlackErrorResponse{Err: "channel_not_found"}
+			case slackDown && channelID == "ng":
+				return "", "", slack.SlackErrorResponse{Err: "service_unavailable"}
+			}
+			return channelID, "1234.5678", nil
+		},
+	}
+
+	t.Run("sender retries failed message in sync mode", func(t *testing.T) {
+		uc := usecase.New(
+			usecase.WithSlack(slackMock),
+			usecase.WithPolicy(mockPolicy),
+			usecase.WithOutbox(outbox),
+		)
+
+		slackDown = true
+		_, err := uc.HandleMessage(ctx, "test", &model.MessageQueryInput{})
+		gt.Error(t, err)
+
+		// Failed messages must not be replayed because the sender retries
+		gt.A(t, gt.R1(outbox.List(ctx)).NoError(t)).Length(0)
+	})
+
+	t.Run("only transient failure remains in async mode", func(t *testing.T) {
+		uc := usecase.New(
+			usecase.WithSlack(slackMock),
+			usecase.WithPolicy(mockPolicy),
+			usecase.WithOutbox(outbox),
+			usecase.WithAsync(10, 1),
+		)
+
+		slackDown = true
+		gt.R1(uc.HandleMessage(ctx, "test", &model.MessageQueryInput{})).NoError(t)
+		gt.NoError(t, uc.Close(ctx))
+
+		list := gt.R1(outbox.List(ctx)).NoError(t)
+		gt.A(t, list).Length(1)
+		gt.Equal(t, list[0].Message.Channel, "ng")
+		gt.Equal(t, list[0].Schema, "test")
+	})
+
+	t.Run("replay pending message", func(t *testing.T) {
+		uc := usecase.New(
+			usecase.WithSlack(slackMock),
+			usecase.WithPolicy(mockPolicy),
+			usecase.WithOutbox(outbox),
+		)
+
+		slackDown = false
+		calls := len(slackMock.PostMessageContextCalls())
+		gt.NoError(t, uc.ReplayOutbox(ctx))
+		gt.A(t, slackMock.PostMessageContextCalls()).Length(calls + 1)
+		gt.Equal(t, slackMock.PostMessageContextCalls()[calls].ChannelID, "ng")
+		gt.A(t, gt.R1(outbox.List(ctx)).NoError(t)).Length(0)
+	})
+}
+
+func TestReplayOutboxOverQueueSize(t *testing.T) {
+	ctx := context.Background()
+	outbox := gt.R1(file.NewOutbox(t.TempDir())).NoError(t)
+	for i := 0; i < 20; i++ {
+		gt.NoError(t, outbox.Put(ctx, model.NewDelivery("test", &model.MessageQueryInput{}, model.Message{Channel: "ch1"})))
+	}
+
+	slackMock := &mock.SlackMock{
+		PostMessageContextFunc: func(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
+			return channelID, "1234.5678", nil
+		},
+	}
+	uc := usecase.New(
+		usecase.WithSlack(slackMock),
+		usecase.WithOutbox(outbox),
+		usecase.WithAsync(5, 1),
+	)
+
+	// Messages not fitting in the queue are delivered inline
+	gt.NoError(t, uc.ReplayOutbox(ctx))
+	gt.NoError(t, uc.Close(ctx))
+	gt.A(t, slackMock.PostMessageContextCalls()).Length(20)
+	gt.A(t, gt.R1(outbox.List(ctx)).NoError(t)).Length(0)
+}
+
+func TestOutboxPutFailure(t *testing.T) {
+	mockPolicy := &mock.PolicyMock{
+		QueryFunc: func(ctx context.Context, query string, input, output any, options ...opac.QueryOption) error {
+			testutil.Transcode(t, output, model.MessageQueryOutput{
+				Messages: []model.Message{{Channel: "ch1"}},
+			})
+			return nil
+		},
+	}
+	slackMock := &mock.SlackMock{}
+	outboxMock := &mock.OutboxMock{
+		PutFunc: func(ctx context.Context, d *model.Delivery) error {
+			return errors.New("disk full")
+		},
+	}
+
+	uc := usecase.New(
+		usecase.WithSlack(slackMock),
+		usecase.WithPolicy(mockPolicy),
+		usecase.WithOutbox(outboxMock),
+	)
+
+	_, err := uc.HandleMessage(context.Background(), "test", &model.MessageQueryInput{})
+	gt.Error(t, err)
+	gt.A(t, slackMock.PostMessageContextCalls()).Length(0)
+}
@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+	"github.com/m-mizutani/nounify/pkg/domain/types"
+	"github.com/m-mizutani/nounify/pkg/utils/ctxutil"
+	"github.com/m-mizutani/nounify/pkg/utils/errutil"
+)
+
+type queueItem struct {
+	ctx      context.Context
+	delivery *model.Delivery
+}
+
+// queue is an in-process bounded queue with a worker pool to deliver messages asynchronously.
+type queue struct {
+	items  chan queueItem
+	wg     sync.WaitGroup
+	mutex  sync.RWMutex
+	closed bool
+}
+
+func newQueue(size, workers int, deliver func(ctx context.Context, d *model.Delivery) error) *queue {
+	q := &queue{
+		items: make(chan queueItem, size),
+	}
+
+	for i := 0; i < workers; i++ {
+		q.wg.Add(1)
+		go func() {
+			defer q.wg.Done()
+			for item := range q.items {
+				if err := deliver(item.ctx, item.delivery); err != nil {
+					errutil.Handle(item.ctx, "failed to deliver queued message",
+						goerr.Wrap(err).With("delivery_id", item.delivery.ID).With("channel", item.delivery.Message.Channel),
+					)
+				}
+			}
+		}()
+	}
+
+	return q
+}
+
+// push adds a delivery to the queue without blocking. The context is detached from cancellation of the HTTP request but keeps values such as logger.
+func (x *queue) push(ctx context.Context, d *model.Delivery) error {
+	x.mutex.RLock()
+	defer x.mutex.RUnlock()
+
+	if x.closed {
+		return goerr.Wrap(types.ErrQueueClosed).With("delivery_id", d.ID)
+	}
+
+	select {
+	case x.items <- queueItem{ctx: context.WithoutCancel(ctx), delivery: d}:
+		return nil
+	default:
+		return goerr.Wrap(types.ErrQueueFull).With("delivery_id", d.ID).With("size", cap(x.items))
+	}
+}
+
+// close stops accepting new deliveries and waits until workers drain remaining ones or ctx is done.
+func (x *queue) close(ctx context.Context) error {
+	x.mutex.Lock()
+	if !x.closed {
+		x.closed = true
+		close(x.items)
+	}
+	x.mutex.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		x.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		remaining := len(x.items)
+		ctxutil.Logger(ctx).Error("gave up draining delivery queue", "remaining", remaining)
+		return goerr.Wrap(ctx.Err(), "failed to drain delivery queue").With("remaining", remaining)
+	}
+}
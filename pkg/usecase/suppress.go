@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+	"github.com/m-mizutani/nounify/pkg/domain/types"
+	"github.com/m-mizutani/nounify/pkg/utils/ctxutil"
+	"github.com/m-mizutani/nounify/pkg/utils/errutil"
+)
+
+func suppressionKey(msg model.Message) string {
+	return "suppress:" + msg.DedupKey
+}
+
+// suppress returns true if a message with the same dedup_key has been delivered within suppress_for.
+func (x *UseCases) suppress(ctx context.Context, msg model.Message) (bool, error) {
+	if msg.DedupKey == "" || msg.SuppressFor == "" {
+		return false, nil
+	}
+
+	period, err := time.ParseDuration(msg.SuppressFor)
+	if err != nil {
+		return false, goerr.Wrap(types.ErrInvalidPolicyOutput.Wrap(err), "invalid suppress_for").With("suppress_for", msg.SuppressFor)
+	}
+
+	if x.suppression == nil {
+		ctxutil.Logger(ctx).Warn("suppression store is not configured, ignore dedup_key", "dedup_key", msg.DedupKey)
+		return false, nil
+	}
+
+	added, err := x.suppression.Add(ctx, suppressionKey(msg), period)
+	if err != nil {
+		return false, goerr.Wrap(err, "failed to add suppression key").With("dedup_key", msg.DedupKey)
+	}
+	if !added {
+		ctxutil.Logger(ctx).Info("message suppressed",
+			"dedup_key", msg.DedupKey,
+			"suppress_for", msg.SuppressFor,
+			"channel", msg.Channel,
+			"title", msg.Title,
+		)
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// releaseSuppression allows the next message with the same dedup_key to be delivered because this one was not delivered.
+func (x *UseCases) releaseSuppression(ctx context.Context, msg model.Message) {
+	if x.suppression == nil || msg.DedupKey == "" || msg.SuppressFor == "" {
+		return
+	}
+
+	if err := x.suppression.Remove(ctx, suppressionKey(msg)); err != nil {
+		errutil.Handle(ctx, "failed to remove suppression key", goerr.Wrap(err).With("dedup_key", msg.DedupKey))
+	}
+}
@@ -0,0 +1,86 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/nounify/pkg/domain/mock"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+	"github.com/m-mizutani/nounify/pkg/domain/types"
+	"github.com/m-mizutani/nounify/pkg/infra/memory"
+	"github.com/m-mizutani/nounify/pkg/usecase"
+	"github.com/m-mizutani/nounify/pkg/utils/testutil"
+	"github.com/m-mizutani/opac"
+	"github.com/slack-go/slack"
+)
+
+func TestSuppression(t *testing.T) {
+	var messages []model.Message
+	mockPolicy := &mock.PolicyMock{
+		QueryFunc: func(ctx context.Context, query string, input, output any, options ...opac.QueryOption) error {
+			testutil.Transcode(t, output, model.MessageQueryOutput{Messages: messages})
+			return nil
+		},
+	}
+
+	var slackErr error
+	slackMock := &mock.SlackMock{
+		PostMessageContextFunc: func(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
+			return channelID, "1234.5678", slackErr
+		},
+	}
+
+	uc := usecase.New(
+		usecase.WithSlack(slackMock),
+		usecase.WithPolicy(mockPolicy),
+		usecase.WithSuppression(memory.NewDedupStore()),
+	)
+	ctx := context.Background()
+	input := &model.MessageQueryInput{}
+
+	messages = []model.Message{
+		{Channel: "ch1", Title: "incident opened", DedupKey: "incident-1", SuppressFor: "30m"},
+		{Channel: "ch1", Title: "incident updated", DedupKey: "incident-1", SuppressFor: "30m"},
+		{Channel: "ch1", Title: "other incident", DedupKey: "incident-2", SuppressFor: "30m"},
+		{Channel: "ch1", Title: "no key"},
+	}
+	report := gt.R1(uc.HandleMessage(ctx, "test", input)).NoError(t)
+	gt.Equal(t, report.Results[0].Status, types.DeliverySucceeded)
+	gt.Equal(t, report.Results[1].Status, types.DeliverySuppressed)
+	gt.Equal(t, report.Results[2].Status, types.DeliverySucceeded)
+	gt.Equal(t, report.Results[3].Status, types.DeliverySucceeded)
+	gt.A(t, slackMock.PostMessageContextCalls()).Length(3)
+
+	t.Run("suppressed in next request", func(t *testing.T) {
+		messages = []model.Message{
+			{Channel: "ch1", DedupKey: "incident-1", SuppressFor: "30m"},
+		}
+		report := gt.R1(uc.HandleMessage(ctx, "test", input)).NoError(t)
+		gt.Equal(t, report.Results[0].Status, types.DeliverySuppressed)
+		gt.A(t, slackMock.PostMessageContextCalls()).Length(3)
+	})
+
+	t.Run("failed message does not suppress next one", func(t *testing.T) {
+		messages = []model.Message{
+			{Channel: "ch1", DedupKey: "incident-3", SuppressFor: "30m"},
+		}
+		slackErr = errors.New("service_unavailable")
+		_, err := uc.HandleMessage(ctx, "test", input)
+		gt.Error(t, err)
+
+		slackErr = nil
+		report := gt.R1(uc.HandleMessage(ctx, "test", input)).NoError(t)
+		gt.Equal(t, report.Results[0].Status, types.DeliverySucceeded)
+	})
+
+	t.Run("invalid suppress_for", func(t *testing.T) {
+		messages = []model.Message{
+			{Channel: "ch1", DedupKey: "incident-4", SuppressFor: "30 minutes"},
+		}
+		report, err := uc.HandleMessage(ctx, "test", input)
+		gt.Error(t, err)
+		gt.Equal(t, report.Results[0].Status, types.DeliveryFailed)
+	})
+}
@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/nounify/pkg/domain/interfaces"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+	"github.com/m-mizutani/nounify/pkg/utils/ctxutil"
+	"github.com/m-mizutani/nounify/pkg/utils/errutil"
+	"github.com/slack-go/slack"
+)
+
+// keyLock serializes operations with the same key, e.g. to avoid two messages becoming thread parents at the same time. A lock is removed when no one holds or waits for it, to not keep every key in memory.
+type keyLock struct {
+	mutex sync.Mutex
+	locks map[string]*keyLockEntry
+}
+
+type keyLockEntry struct {
+	mutex sync.Mutex
+	// refs is number of callers holding or waiting for the lock, guarded by keyLock.mutex
+	refs int
+}
+
+func (x *keyLock) lock(key string) func() {
+	x.mutex.Lock()
+	if x.locks == nil {
+		x.locks = make(map[string]*keyLockEntry)
+	}
+	entry, ok := x.locks[key]
+	if !ok {
+		entry = &keyLockEntry{}
+		x.locks[key] = entry
+	}
+	entry.refs++
+	x.mutex.Unlock()
+
+	entry.mutex.Lock()
+	return func() {
+		entry.mutex.Unlock()
+
+		x.mutex.Lock()
+		defer x.mutex.Unlock()
+		entry.refs--
+		if entry.refs == 0 {
+			delete(x.locks, key)
+		}
+	}
+}
+
+func threadRefKey(msg model.Message) string {
+	return "thread:" + slackChannelKey(msg) + ":" + msg.ThreadKey
+}
+
+// slackChannelKey identifies the channel across workspaces. The channel of the default workspace is kept as it is for compatibility of saved references.
+func slackChannelKey(msg model.Message) string {
+	if msg.Workspace == "" {
+		return msg.Channel
+	}
+	return msg.Workspace + "/" + msg.Channel
+}
+
+// postThreadMessage posts the first message with the thread key as a parent, and following messages as replies. It returns the channel ID, timestamp of the message and timestamp of the thread.
+func (x *UseCases) postThreadMessage(ctx context.Context, client interfaces.Slack, msg model.Message, options []slack.MsgOption) (string, string, string, error) {
+	if x.refs == nil {
+		ctxutil.Logger(ctx).Warn("message ref store is not configured, ignore thread_key", "thread_key", msg.ThreadKey)
+		channelID, ts, err := client.PostMessageContext(ctx, msg.Channel, options...)
+		return channelID, ts, "", err
+	}
+
+	key := threadRefKey(msg)
+	unlock := x.keyLock.lock(key)
+	defer unlock()
+
+	parent, err := x.refs.Get(ctx, key)
+	if err != nil {
+		return "", "", "", goerr.Wrap(err, "failed to get thread parent").With("thread_key", msg.ThreadKey)
+	}
+
+	if parent != nil {
+		options = append(options, slack.MsgOptionTS(parent.Timestamp))
+		channelID, ts, err := client.PostMessageContext(ctx, parent.Channel, options...)
+		if err != nil {
+			return "", "", "", err
+		}
+		ctxutil.Logger(ctx).Debug("posted thread reply", "thread_key", msg.ThreadKey, "thread_ts", parent.Timestamp)
+		return channelID, ts, parent.Timestamp, nil
+	}
+
+	channelID, ts, err := client.PostMessageContext(ctx, msg.Channel, options...)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	ref := &model.MessageRef{
+		Channel:   channelID,
+		Timestamp: ts,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := x.refs.Put(ctx, key, ref); err != nil {
+		// The message has been posted, then do not fail the delivery. Next message with the key will be a new parent.
+		errutil.Handle(ctx, "failed to save thread parent", goerr.Wrap(err).With("thread_key", msg.ThreadKey))
+	}
+
+	return channelID, ts, ts, nil
+}
@@ -0,0 +1,86 @@
+package usecase_test
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/nounify/pkg/domain/mock"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+	"github.com/m-mizutani/nounify/pkg/infra/file"
+	"github.com/m-mizutani/nounify/pkg/usecase"
+	"github.com/m-mizutani/nounify/pkg/utils/testutil"
+	"github.com/m-mizutani/opac"
+	"github.com/slack-go/slack"
+)
+
+// msgValues returns parameters of Slack API built from options
+func msgValues(t *testing.T, options []slack.MsgOption) url.Values {
+	t.Helper()
+	_, values := gt.R2(slack.UnsafeApplyMsgOptions("token", "channel", "https://slack.com/api/", options...)).NoError(t)
+	return values
+}
+
+func TestThread(t *testing.T) {
+	var messages []model.Message
+	mockPolicy := &mock.PolicyMock{
+		QueryFunc: func(ctx context.Context, query string, input, output any, options ...opac.QueryOption) error {
+			testutil.Transcode(t, output, model.MessageQueryOutput{Messages: messages})
+			return nil
+		},
+	}
+
+	slackMock := &mock.SlackMock{}
+	slackMock.PostMessageContextFunc = func(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
+		n := len(slackMock.PostMessageContextCalls())
+		return "C_" + channelID, fmt.Sprintf("1000.%04d", n), nil
+	}
+
+	refs := gt.R1(file.NewMessageRefStore(t.TempDir())).NoError(t)
+	uc := usecase.New(
+		usecase.WithSlack(slackMock),
+		usecase.WithPolicy(mockPolicy),
+		usecase.WithMessageRefStore(refs),
+	)
+
+	ctx := context.Background()
+	messages = []model.Message{
+		{Channel: "dev", Title: "PR opened", ThreadKey: "pr-1"},
+		{Channel: "dev", Title: "PR review requested", ThreadKey: "pr-1"},
+		{Channel: "dev", Title: "Other PR opened", ThreadKey: "pr-2"},
+		{Channel: "ops", Title: "PR opened", ThreadKey: "pr-1"},
+	}
+	gt.R1(uc.HandleMessage(ctx, "test", &model.MessageQueryInput{})).NoError(t)
+
+	messages = []model.Message{
+		{Channel: "dev", Title: "PR merged", ThreadKey: "pr-1"},
+	}
+	gt.R1(uc.HandleMessage(ctx, "test", &model.MessageQueryInput{})).NoError(t)
+
+	calls := slackMock.PostMessageContextCalls()
+	gt.A(t, calls).Length(5)
+
+	// parent of pr-1 in dev
+	gt.Equal(t, calls[0].ChannelID, "dev")
+	gt.Equal(t, msgValues(t, calls[0].Options).Get("thread_ts"), "")
+
+	// reply to the parent with channel ID
+	gt.Equal(t, calls[1].ChannelID, "C_dev")
+	gt.Equal(t, msgValues(t, calls[1].Options).Get("thread_ts"), "1000.0001")
+
+	// different key is a new parent
+	gt.Equal(t, msgValues(t, calls[2].Options).Get("thread_ts"), "")
+
+	// same key in different channel is a new parent
+	gt.Equal(t, calls[3].ChannelID, "ops")
+	gt.Equal(t, msgValues(t, calls[3].Options).Get("thread_ts"), "")
+
+	// reply in another request
+	gt.Equal(t, calls[4].ChannelID, "C_dev")
+	gt.Equal(t, msgValues(t, calls[4].Options).Get("thread_ts"), "1000.0001")
+
+	// Locks of thread keys are released after delivery
+	gt.Equal(t, uc.LockedKeys(), 0)
+}
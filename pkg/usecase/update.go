@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/nounify/pkg/domain/interfaces"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+	"github.com/m-mizutani/nounify/pkg/utils/ctxutil"
+	"github.com/m-mizutani/nounify/pkg/utils/errutil"
+	"github.com/m-mizutani/nounify/pkg/utils/slackmsg"
+	"github.com/slack-go/slack"
+)
+
+// messageGoneErrors are error codes of chat.update that mean the original message can not be updated anymore
+var messageGoneErrors = map[string]struct{}{
+	"message_not_found":   {},
+	"cant_update_message": {},
+	"edit_window_closed":  {},
+}
+
+func isMessageGone(err error) bool {
+	var respErr slack.SlackErrorResponse
+	if errors.As(err, &respErr) {
+		_, ok := messageGoneErrors[respErr.Err]
+		return ok
+	}
+	return false
+}
+
+func updateRefKey(msg model.Message) string {
+	return "update:" + slackChannelKey(msg) + ":" + msg.UpdateKey
+}
+
+// updateMessage replaces the message posted with the same update key. If there is no such message or it has been deleted, a new message is posted and becomes the target of following updates.
+func (x *UseCases) updateMessage(ctx context.Context, client interfaces.Slack, msg model.Message) error {
+	if x.refs == nil {
+		ctxutil.Logger(ctx).Warn("message ref store is not configured, ignore update_key", "update_key", msg.UpdateKey)
+		if _, _, err := x.sendMessage(ctx, client, msg); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	key := updateRefKey(msg)
+	unlock := x.keyLock.lock(key)
+	defer unlock()
+
+	// Validate the content before updating to avoid posting a new message for invalid blocks
+	content, err := slackmsg.NewContent(msg)
+	if err != nil {
+		return err
+	}
+
+	ref, err := x.refs.Get(ctx, key)
+	if err != nil {
+		return goerr.Wrap(err, "failed to get message to update").With("update_key", msg.UpdateKey)
+	}
+
+	if ref != nil {
+		// Icon can not be changed by chat.update, then only the content is replaced
+		_, _, _, err := client.UpdateMessageContext(ctx, ref.Channel, ref.Timestamp, content.MsgOptions()...)
+		if err == nil {
+			ctxutil.Logger(ctx).Debug("updated message", "update_key", msg.UpdateKey, "ts", ref.Timestamp)
+			x.uploadFiles(ctx, client, msg, ref.Channel, "")
+			return nil
+		}
+		if !isMessageGone(err) {
+			return err
+		}
+		ctxutil.Logger(ctx).Info("message to update is gone, post a new one",
+			"update_key", msg.UpdateKey,
+			"ts", ref.Timestamp,
+			"error", err,
+		)
+	}
+
+	channelID, ts, err := x.sendMessage(ctx, client, msg)
+	if err != nil {
+		return err
+	}
+
+	newRef := &model.MessageRef{
+		Channel:   channelID,
+		Timestamp: ts,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := x.refs.Put(ctx, key, newRef); err != nil {
+		// The message has been posted, then do not fail the delivery. Next message with the key will be posted as a new one.
+		errutil.Handle(ctx, "failed to save message to update", goerr.Wrap(err).With("update_key", msg.UpdateKey))
+	}
+
+	return nil
+}
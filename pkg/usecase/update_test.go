@@ -0,0 +1,102 @@
+package usecase_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/nounify/pkg/domain/mock"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+	"github.com/m-mizutani/nounify/pkg/infra/memory"
+	"github.com/m-mizutani/nounify/pkg/usecase"
+	"github.com/m-mizutani/nounify/pkg/utils/testutil"
+	"github.com/m-mizutani/opac"
+	"github.com/slack-go/slack"
+)
+
+func TestUpdateMessage(t *testing.T) {
+	var messages []model.Message
+	mockPolicy := &mock.PolicyMock{
+		QueryFunc: func(ctx context.Context, query string, input, output any, options ...opac.QueryOption) error {
+			testutil.Transcode(t, output, model.MessageQueryOutput{Messages: messages})
+			return nil
+		},
+	}
+
+	var updateErr error
+	slackMock := &mock.SlackMock{}
+	slackMock.PostMessageContextFunc = func(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
+		n := len(slackMock.PostMessageContextCalls())
+		return "C_" + channelID, fmt.Sprintf("1000.%04d", n), nil
+	}
+	slackMock.UpdateMessageContextFunc = func(ctx context.Context, channelID, timestamp string, options ...slack.MsgOption) (string, string, string, error) {
+		if updateErr != nil {
+			return "", "", "", updateErr
+		}
+		return channelID, timestamp, "", nil
+	}
+
+	uc := usecase.New(
+		usecase.WithSlack(slackMock),
+		usecase.WithPolicy(mockPolicy),
+		usecase.WithMessageRefStore(memory.NewMessageRefStore()),
+	)
+	ctx := context.Background()
+
+	handle := func(title string) {
+		t.Helper()
+		messages = []model.Message{
+			{Channel: "deploy", Title: title, UpdateKey: "release-1"},
+		}
+		gt.R1(uc.HandleMessage(ctx, "test", &model.MessageQueryInput{})).NoError(t)
+	}
+
+	t.Run("first message is posted", func(t *testing.T) {
+		handle("deploying")
+		gt.A(t, slackMock.PostMessageContextCalls()).Length(1)
+		gt.A(t, slackMock.UpdateMessageContextCalls()).Length(0)
+	})
+
+	t.Run("following message updates the posted one", func(t *testing.T) {
+		handle("deployed")
+		gt.A(t, slackMock.PostMessageContextCalls()).Length(1)
+
+		calls := slackMock.UpdateMessageContextCalls()
+		gt.A(t, calls).Length(1)
+		gt.Equal(t, calls[0].ChannelID, "C_deploy")
+		gt.Equal(t, calls[0].Timestamp, "1000.0001")
+		gt.S(t, msgValues(t, calls[0].Options).Get("attachments")).Contains("deployed")
+	})
+
+	t.Run("post a new message if the original is gone", func(t *testing.T) {
+		updateErr = slack.SlackErrorResponse{Err: "message_not_found"}
+		handle("rolled back")
+		gt.A(t, slackMock.UpdateMessageContextCalls()).Length(2)
+		gt.A(t, slackMock.PostMessageContextCalls()).Length(2)
+	})
+
+	t.Run("update the new message", func(t *testing.T) {
+		updateErr = nil
+		handle("redeployed")
+
+		calls := slackMock.UpdateMessageContextCalls()
+		gt.A(t, calls).Length(3)
+		gt.Equal(t, calls[2].Timestamp, "1000.0002")
+		gt.A(t, slackMock.PostMessageContextCalls()).Length(2)
+	})
+
+	t.Run("other error fails delivery", func(t *testing.T) {
+		updateErr = slack.SlackErrorResponse{Err: "not_in_channel"}
+		messages = []model.Message{
+			{Channel: "deploy", Title: "failed", UpdateKey: "release-1"},
+		}
+		_, err := uc.HandleMessage(ctx, "test", &model.MessageQueryInput{})
+		gt.Error(t, err)
+		gt.A(t, slackMock.PostMessageContextCalls()).Length(2)
+	})
+
+	t.Run("locks of update keys are released", func(t *testing.T) {
+		gt.Equal(t, uc.LockedKeys(), 0)
+	})
+}
@@ -1,31 +1,135 @@
 package usecase
 
-import "github.com/m-mizutani/nounify/pkg/domain/interfaces"
+import (
+	"context"
+	"time"
+
+	"github.com/m-mizutani/nounify/pkg/domain/interfaces"
+	"github.com/m-mizutani/nounify/pkg/domain/types"
+)
 
 type UseCases struct {
-	slack  interfaces.Slack
-	policy interfaces.Policy
+	slack      interfaces.Slack
+	workspaces map[string]interfaces.Slack
+	notifiers  map[types.Destination]interfaces.Notifier
+	policy     interfaces.Policy
+	outbox     interfaces.Outbox
+	deadLetter interfaces.DeadLetterStore
+	dedup      interfaces.DedupStore
+	dedupTTL   time.Duration
+
+	suppression interfaces.DedupStore
+	refs        interfaces.MessageRefStore
+	keyLock     keyLock
+
+	asyncQueueSize int
+	asyncWorkers   int
+	queue          *queue
+
+	digest *digest
 }
 
 func New(options ...Option) *UseCases {
-	uc := &UseCases{}
+	uc := &UseCases{
+		notifiers:  make(map[types.Destination]interfaces.Notifier),
+		workspaces: make(map[string]interfaces.Slack),
+	}
 	for _, option := range options {
 		option(uc)
 	}
 
+	uc.digest = newDigest(uc.flushDigest)
+	if uc.asyncWorkers > 0 {
+		uc.queue = newQueue(uc.asyncQueueSize, uc.asyncWorkers, uc.deliverInBackground)
+	}
+
 	return uc
 }
 
+// Close stops accepting new messages and waits for buffered and queued messages to be delivered until ctx is done.
+func (x *UseCases) Close(ctx context.Context) error {
+	// Digest messages must be flushed before closing the queue
+	if err := x.digest.close(ctx); err != nil {
+		return err
+	}
+
+	if x.queue != nil {
+		if err := x.queue.close(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type Option func(*UseCases)
 
+// WithSlack sets Slack API client. If not set, messages to Slack are delivered by the notifier of slack destination (e.g. incoming webhook).
 func WithSlack(slack interfaces.Slack) Option {
 	return func(uc *UseCases) {
 		uc.slack = slack
 	}
 }
 
+// WithSlackWorkspace adds Slack API client for the named workspace. Messages with the workspace are posted by the client.
+func WithSlackWorkspace(name string, slack interfaces.Slack) Option {
+	return func(uc *UseCases) {
+		uc.workspaces[name] = slack
+	}
+}
+
+// WithNotifier enables delivery of messages with the destination by the notifier.
+func WithNotifier(dest types.Destination, notifier interfaces.Notifier) Option {
+	return func(uc *UseCases) {
+		uc.notifiers[dest] = notifier
+	}
+}
+
 func WithPolicy(policy interfaces.Policy) Option {
 	return func(uc *UseCases) {
 		uc.policy = policy
 	}
 }
+
+// WithOutbox enables persisting messages before delivery. A message is removed from outbox only after it is delivered.
+func WithOutbox(outbox interfaces.Outbox) Option {
+	return func(uc *UseCases) {
+		uc.outbox = outbox
+	}
+}
+
+// WithDeadLetterStore enables saving messages that failed to be delivered.
+func WithDeadLetterStore(store interfaces.DeadLetterStore) Option {
+	return func(uc *UseCases) {
+		uc.deadLetter = store
+	}
+}
+
+// WithDedup enables deduplication of requests by delivery ID (e.g. X-GitHub-Delivery and SNS MessageId) or dedup_key from policy. A duplicated request within ttl is accepted without delivering messages.
+func WithDedup(store interfaces.DedupStore, ttl time.Duration) Option {
+	return func(uc *UseCases) {
+		uc.dedup = store
+		uc.dedupTTL = ttl
+	}
+}
+
+// WithSuppression enables suppression of messages by dedup_key and suppress_for of the message.
+func WithSuppression(store interfaces.DedupStore) Option {
+	return func(uc *UseCases) {
+		uc.suppression = store
+	}
+}
+
+// WithMessageRefStore enables thread_key of the message by saving references to posted messages.
+func WithMessageRefStore(store interfaces.MessageRefStore) Option {
+	return func(uc *UseCases) {
+		uc.refs = store
+	}
+}
+
+// WithAsync enables asynchronous delivery. Messages are put into a bounded queue with queueSize and delivered by workers in background.
+func WithAsync(queueSize, workers int) Option {
+	return func(uc *UseCases) {
+		uc.asyncQueueSize = queueSize
+		uc.asyncWorkers = workers
+	}
+}
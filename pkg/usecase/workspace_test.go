@@ -0,0 +1,106 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/nounify/pkg/domain/mock"
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+	"github.com/m-mizutani/nounify/pkg/domain/types"
+	"github.com/m-mizutani/nounify/pkg/infra/memory"
+	"github.com/m-mizutani/nounify/pkg/usecase"
+	"github.com/m-mizutani/nounify/pkg/utils/testutil"
+	"github.com/m-mizutani/opac"
+	"github.com/slack-go/slack"
+)
+
+func TestSlackWorkspace(t *testing.T) {
+	var messages []model.Message
+	mockPolicy := &mock.PolicyMock{
+		QueryFunc: func(ctx context.Context, query string, input, output any, options ...opac.QueryOption) error {
+			testutil.Transcode(t, output, model.MessageQueryOutput{Messages: messages})
+			return nil
+		},
+	}
+
+	newSlackMock := func(ts string) *mock.SlackMock {
+		return &mock.SlackMock{
+			PostMessageContextFunc: func(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
+				return "C_" + channelID, ts, nil
+			},
+		}
+	}
+	internal := newSlackMock("1000.0001")
+	vendor := newSlackMock("2000.0001")
+
+	uc := usecase.New(
+		usecase.WithSlack(internal),
+		usecase.WithSlackWorkspace("vendor", vendor),
+		usecase.WithPolicy(mockPolicy),
+		usecase.WithMessageRefStore(memory.NewMessageRefStore()),
+	)
+	ctx := context.Background()
+
+	t.Run("route messages by workspace", func(t *testing.T) {
+		messages = []model.Message{
+			{Channel: "alerts", Title: "internal", ThreadKey: "incident-1"},
+			{Workspace: "vendor", Channel: "alerts", Title: "vendor", ThreadKey: "incident-1"},
+			{Workspace: "unknown", Channel: "alerts", Title: "unknown"},
+		}
+		report, err := uc.HandleMessage(ctx, "test", &model.MessageQueryInput{})
+		gt.Error(t, err)
+
+		gt.Equal(t, report.Results[0].Status, types.DeliverySucceeded)
+		gt.Equal(t, report.Results[1].Workspace, "vendor")
+		gt.Equal(t, report.Results[1].Status, types.DeliverySucceeded)
+		gt.Equal(t, report.Results[2].Workspace, "unknown")
+		gt.Equal(t, report.Results[2].Status, types.DeliveryFailed)
+		gt.S(t, report.Results[2].Error).Contains("Slack workspace is not configured")
+
+		gt.A(t, internal.PostMessageContextCalls()).Length(1)
+		gt.A(t, vendor.PostMessageContextCalls()).Length(1)
+	})
+
+	t.Run("threads are separated by workspace", func(t *testing.T) {
+		messages = []model.Message{
+			{Workspace: "vendor", Channel: "alerts", Title: "vendor reply", ThreadKey: "incident-1"},
+		}
+		gt.R1(uc.HandleMessage(ctx, "test", &model.MessageQueryInput{})).NoError(t)
+
+		calls := vendor.PostMessageContextCalls()
+		gt.A(t, calls).Length(2)
+		gt.Equal(t, msgValues(t, calls[1].Options).Get("thread_ts"), "2000.0001")
+		gt.A(t, internal.PostMessageContextCalls()).Length(1)
+	})
+}
+
+func TestSlackWorkspaceWithoutDefault(t *testing.T) {
+	mockPolicy := &mock.PolicyMock{
+		QueryFunc: func(ctx context.Context, query string, input, output any, options ...opac.QueryOption) error {
+			testutil.Transcode(t, output, model.MessageQueryOutput{
+				Messages: []model.Message{
+					{Workspace: "vendor", Channel: "alerts", Title: "vendor"},
+					{Channel: "alerts", Title: "default"},
+				},
+			})
+			return nil
+		},
+	}
+	vendor := &mock.SlackMock{
+		PostMessageContextFunc: func(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
+			return "C_" + channelID, "2000.0001", nil
+		},
+	}
+
+	uc := usecase.New(
+		usecase.WithSlackWorkspace("vendor", vendor),
+		usecase.WithPolicy(mockPolicy),
+	)
+
+	report, err := uc.HandleMessage(context.Background(), "test", &model.MessageQueryInput{})
+	gt.Error(t, err)
+	gt.Equal(t, report.Results[0].Status, types.DeliverySucceeded)
+	gt.Equal(t, report.Results[1].Status, types.DeliveryFailed)
+	gt.S(t, report.Results[1].Error).Contains("default Slack workspace is not configured")
+}
@@ -0,0 +1,270 @@
+package slackmsg
+
+import (
+	"encoding/json"
+	"slices"
+	"unicode/utf8"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/nounify/pkg/domain/types"
+	"github.com/slack-go/slack"
+)
+
+// Limits of Block Kit for messages. See https://api.slack.com/reference/block-kit/blocks
+const (
+	maxBlocks          = 50
+	maxBlockIDLen      = 255
+	maxHeaderTextLen   = 150
+	maxSectionTextLen  = 3000
+	maxSectionFields   = 10
+	maxFieldTextLen    = 2000
+	maxContextElements = 10
+	maxActionElements  = 25
+	maxAltTextLen      = 2000
+	maxImageURLLen     = 3000
+	maxVideoTitleLen   = 200
+)
+
+type blockValidator func(block map[string]any) error
+
+// blockValidators has block types available in messages
+var blockValidators = map[string]blockValidator{
+	"actions":   validateActionsBlock,
+	"context":   validateContextBlock,
+	"divider":   func(map[string]any) error { return nil },
+	"header":    validateHeaderBlock,
+	"image":     validateImageBlock,
+	"rich_text": validateRichTextBlock,
+	"section":   validateSectionBlock,
+	"video":     validateVideoBlock,
+}
+
+// rawBlock is a block from policy sent as it is. Converting it into types of slack-go drops fields that slack-go does not model, e.g. slack_file of image block.
+type rawBlock map[string]any
+
+func (x rawBlock) BlockType() slack.MessageBlockType {
+	blockType, _ := x["type"].(string)
+	return slack.MessageBlockType(blockType)
+}
+
+func (x rawBlock) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any(x))
+}
+
+// Blocks validates raw Block Kit blocks from policy and returns them as Slack blocks. An error wrapping types.ErrInvalidPolicyOutput is returned if the blocks violate the Block Kit schema.
+func Blocks(raw []map[string]any) ([]slack.Block, error) {
+	if len(raw) > maxBlocks {
+		return nil, goerr.Wrap(types.ErrInvalidPolicyOutput, "too many blocks").With("blocks", len(raw)).With("max", maxBlocks)
+	}
+
+	blockIDs := make(map[string]struct{})
+	blocks := make([]slack.Block, len(raw))
+	for i, block := range raw {
+		if err := validateBlock(block, blockIDs); err != nil {
+			return nil, goerr.Wrap(err).With("index", i).With("type", block["type"])
+		}
+		blocks[i] = rawBlock(block)
+	}
+
+	// Blocks must be encodable to be sent
+	if _, err := json.Marshal(raw); err != nil {
+		return nil, goerr.Wrap(types.ErrInvalidPolicyOutput.Wrap(err), "failed to encode blocks")
+	}
+
+	return blocks, nil
+}
+
+func validateBlock(block map[string]any, blockIDs map[string]struct{}) error {
+	blockType, _ := block["type"].(string)
+	validate, ok := blockValidators[blockType]
+	if !ok {
+		return goerr.Wrap(types.ErrInvalidPolicyOutput, "unsupported block type")
+	}
+
+	if v, ok := block["block_id"]; ok {
+		blockID, ok := v.(string)
+		if !ok || blockID == "" || utf8.RuneCountInString(blockID) > maxBlockIDLen {
+			return goerr.Wrap(types.ErrInvalidPolicyOutput, "invalid block_id").With("block_id", v)
+		}
+		if _, dup := blockIDs[blockID]; dup {
+			return goerr.Wrap(types.ErrInvalidPolicyOutput, "duplicated block_id").With("block_id", blockID)
+		}
+		blockIDs[blockID] = struct{}{}
+	}
+
+	return validate(block)
+}
+
+func invalidField(name, reason string) *goerr.Error {
+	return goerr.Wrap(types.ErrInvalidPolicyOutput, reason).With("field", name)
+}
+
+// validateText checks a text object. Allowed types are plain_text and mrkdwn if textTypes is not specified.
+func validateText(v any, name string, maxLen int, textTypes ...string) error {
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return invalidField(name, "text object is required")
+	}
+
+	if len(textTypes) == 0 {
+		textTypes = []string{"plain_text", "mrkdwn"}
+	}
+	textType, _ := obj["type"].(string)
+	if !slices.Contains(textTypes, textType) {
+		return invalidField(name, "invalid text type").With("type", obj["type"]).With("allowed", textTypes)
+	}
+
+	text, _ := obj["text"].(string)
+	if text == "" {
+		return invalidField(name, "text is required")
+	}
+	if n := utf8.RuneCountInString(text); n > maxLen {
+		return invalidField(name, "text is too long").With("length", n).With("max", maxLen)
+	}
+
+	return nil
+}
+
+func validateString(v any, name string, maxLen int) error {
+	s, _ := v.(string)
+	if s == "" {
+		return invalidField(name, "string is required")
+	}
+	if n := utf8.RuneCountInString(s); n > maxLen {
+		return invalidField(name, "string is too long").With("length", n).With("max", maxLen)
+	}
+	return nil
+}
+
+// validateElements checks that v is a list of objects with type and returns them. The number of elements is not limited if max is 0.
+func validateElements(v any, name string, max int) ([]map[string]any, error) {
+	list, _ := v.([]any)
+	if len(list) == 0 {
+		return nil, invalidField(name, "elements are required")
+	}
+	if max > 0 && len(list) > max {
+		return nil, invalidField(name, "too many elements").With("elements", len(list)).With("max", max)
+	}
+
+	elements := make([]map[string]any, len(list))
+	for i, e := range list {
+		obj, ok := e.(map[string]any)
+		if !ok {
+			return nil, invalidField(name, "element must be an object").With("element", i)
+		}
+		if t, _ := obj["type"].(string); t == "" {
+			return nil, invalidField(name, "type of element is required").With("element", i)
+		}
+		elements[i] = obj
+	}
+
+	return elements, nil
+}
+
+func validateHeaderBlock(block map[string]any) error {
+	return validateText(block["text"], "text", maxHeaderTextLen, "plain_text")
+}
+
+func validateSectionBlock(block map[string]any) error {
+	text, hasText := block["text"]
+	fields, hasFields := block["fields"]
+	if !hasText && !hasFields {
+		return invalidField("text", "text or fields is required")
+	}
+
+	if hasText {
+		if err := validateText(text, "text", maxSectionTextLen); err != nil {
+			return err
+		}
+	}
+
+	if hasFields {
+		list, _ := fields.([]any)
+		if len(list) == 0 {
+			return invalidField("fields", "fields must not be empty")
+		}
+		if len(list) > maxSectionFields {
+			return invalidField("fields", "too many fields").With("fields", len(list)).With("max", maxSectionFields)
+		}
+		for _, field := range list {
+			if err := validateText(field, "fields", maxFieldTextLen); err != nil {
+				return err
+			}
+		}
+	}
+
+	if accessory, ok := block["accessory"]; ok {
+		if _, err := validateElements([]any{accessory}, "accessory", 1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateContextBlock(block map[string]any) error {
+	elements, err := validateElements(block["elements"], "elements", maxContextElements)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range elements {
+		switch e["type"] {
+		case "plain_text", "mrkdwn":
+			if err := validateText(e, "elements", maxSectionTextLen); err != nil {
+				return err
+			}
+		case "image":
+			if err := validateImage(e); err != nil {
+				return err
+			}
+		default:
+			return invalidField("elements", "unsupported element of context block").With("element_type", e["type"])
+		}
+	}
+
+	return nil
+}
+
+func validateActionsBlock(block map[string]any) error {
+	_, err := validateElements(block["elements"], "elements", maxActionElements)
+	return err
+}
+
+func validateImage(obj map[string]any) error {
+	if _, ok := obj["slack_file"]; !ok {
+		if err := validateString(obj["image_url"], "image_url", maxImageURLLen); err != nil {
+			return err
+		}
+	}
+	return validateString(obj["alt_text"], "alt_text", maxAltTextLen)
+}
+
+func validateImageBlock(block map[string]any) error {
+	if err := validateImage(block); err != nil {
+		return err
+	}
+	if title, ok := block["title"]; ok {
+		if err := validateText(title, "title", maxAltTextLen, "plain_text"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateRichTextBlock(block map[string]any) error {
+	_, err := validateElements(block["elements"], "elements", 0)
+	return err
+}
+
+func validateVideoBlock(block map[string]any) error {
+	if err := validateText(block["title"], "title", maxVideoTitleLen, "plain_text"); err != nil {
+		return err
+	}
+	for _, name := range []string{"video_url", "thumbnail_url"} {
+		if err := validateString(block[name], name, maxImageURLLen); err != nil {
+			return err
+		}
+	}
+	return validateString(block["alt_text"], "alt_text", maxAltTextLen)
+}
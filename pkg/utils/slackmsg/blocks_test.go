@@ -0,0 +1,107 @@
+package slackmsg_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/nounify/pkg/domain/types"
+	"github.com/m-mizutani/nounify/pkg/utils/slackmsg"
+	"github.com/slack-go/slack"
+)
+
+func text(textType, s string) map[string]any {
+	return map[string]any{"type": textType, "text": s}
+}
+
+func TestBlocks(t *testing.T) {
+	testCases := map[string]struct {
+		blocks []map[string]any
+		err    bool
+	}{
+		"valid blocks": {
+			blocks: []map[string]any{
+				{"type": "header", "text": text("plain_text", "Alert")},
+				{"type": "section", "text": text("mrkdwn", "*body*"), "fields": []any{text("mrkdwn", "a"), text("plain_text", "b")}},
+				{"type": "divider", "block_id": "d1"},
+				{"type": "context", "elements": []any{text("mrkdwn", "note"), map[string]any{"type": "image", "image_url": "https://example.com/a.png", "alt_text": "a"}}},
+				{"type": "image", "image_url": "https://example.com/a.png", "alt_text": "a"},
+				{"type": "actions", "elements": []any{map[string]any{"type": "button", "text": text("plain_text", "Open"), "url": "https://example.com"}}},
+			},
+		},
+		"unknown block type": {
+			blocks: []map[string]any{{"type": "unknown"}},
+			err:    true,
+		},
+		"input block is not available in messages": {
+			blocks: []map[string]any{{"type": "input", "label": text("plain_text", "x"), "element": map[string]any{"type": "plain_text_input"}}},
+			err:    true,
+		},
+		"header must be plain_text": {
+			blocks: []map[string]any{{"type": "header", "text": text("mrkdwn", "Alert")}},
+			err:    true,
+		},
+		"header is too long": {
+			blocks: []map[string]any{{"type": "header", "text": text("plain_text", strings.Repeat("a", 151))}},
+			err:    true,
+		},
+		"section without text and fields": {
+			blocks: []map[string]any{{"type": "section"}},
+			err:    true,
+		},
+		"too many fields": {
+			blocks: []map[string]any{{"type": "section", "fields": []any{
+				text("mrkdwn", "1"), text("mrkdwn", "2"), text("mrkdwn", "3"), text("mrkdwn", "4"), text("mrkdwn", "5"), text("mrkdwn", "6"),
+				text("mrkdwn", "7"), text("mrkdwn", "8"), text("mrkdwn", "9"), text("mrkdwn", "10"), text("mrkdwn", "11"),
+			}}},
+			err: true,
+		},
+		"image without alt_text": {
+			blocks: []map[string]any{{"type": "image", "image_url": "https://example.com/a.png"}},
+			err:    true,
+		},
+		"duplicated block_id": {
+			blocks: []map[string]any{{"type": "divider", "block_id": "x"}, {"type": "divider", "block_id": "x"}},
+			err:    true,
+		},
+		"too many blocks": {
+			blocks: func() []map[string]any {
+				blocks := make([]map[string]any, 51)
+				for i := range blocks {
+					blocks[i] = map[string]any{"type": "divider"}
+				}
+				return blocks
+			}(),
+			err: true,
+		},
+	}
+
+	for title, tc := range testCases {
+		t.Run(title, func(t *testing.T) {
+			blocks, err := slackmsg.Blocks(tc.blocks)
+			if tc.err {
+				gt.Error(t, err).Is(types.ErrInvalidPolicyOutput)
+				return
+			}
+			gt.NoError(t, err)
+			gt.A(t, blocks).Length(len(tc.blocks))
+			gt.Equal(t, blocks[0].BlockType(), slack.MBTHeader)
+		})
+	}
+}
+
+func TestBlocksKeepFields(t *testing.T) {
+	// slack_file is not modeled by slack-go, but it must be sent
+	blocks := gt.R1(slackmsg.Blocks([]map[string]any{
+		{
+			"type":       "image",
+			"slack_file": map[string]any{"url": "https://files.slack.com/files-pri/T0-F0/a.png"},
+			"alt_text":   "graph",
+		},
+	})).NoError(t)
+
+	data := gt.R1(json.Marshal(blocks)).NoError(t)
+	gt.S(t, string(data)).Contains(`"slack_file":{"url":"https://files.slack.com/files-pri/T0-F0/a.png"}`)
+	gt.S(t, string(data)).NotContains("image_url")
+}
@@ -0,0 +1,142 @@
+package slackmsg
+
+import (
+	"fmt"
+
+	"github.com/m-mizutani/nounify/pkg/domain/model"
+	"github.com/slack-go/slack"
+)
+
+// Content is the body of a Slack message rendered from the policy output
+type Content struct {
+	Text        string
+	Blocks      []slack.Block
+	Attachments []slack.Attachment
+}
+
+// NewContent renders the message. If the message has raw blocks, they are validated and posted as top level blocks with the title (or body) as notification text. The blocks are wrapped with an attachment only when color is specified to show the color bar. Otherwise the message is rendered as an attachment with Block Kit blocks.
+func NewContent(msg model.Message) (*Content, error) {
+	if len(msg.Blocks) == 0 {
+		return &Content{
+			Attachments: []slack.Attachment{Attachment(msg)},
+		}, nil
+	}
+
+	blocks, err := Blocks(msg.Blocks)
+	if err != nil {
+		return nil, err
+	}
+
+	content := &Content{Text: msg.Title}
+	if content.Text == "" {
+		content.Text = msg.Body
+	}
+
+	if msg.Color != "" {
+		content.Attachments = []slack.Attachment{
+			{
+				Color:  msg.ColorCode(),
+				Blocks: slack.Blocks{BlockSet: blocks},
+			},
+		}
+	} else {
+		content.Blocks = blocks
+	}
+
+	return content, nil
+}
+
+// MsgOptions returns options of chat.postMessage and chat.update for the content
+func (x *Content) MsgOptions() []slack.MsgOption {
+	var options []slack.MsgOption
+	if x.Text != "" {
+		options = append(options, slack.MsgOptionText(x.Text, false))
+	}
+	if len(x.Blocks) > 0 {
+		options = append(options, slack.MsgOptionBlocks(x.Blocks...))
+	}
+	if len(x.Attachments) > 0 {
+		options = append(options, slack.MsgOptionAttachments(x.Attachments...))
+	}
+	return options
+}
+
+// MsgOptions returns options of chat.postMessage to post the message with the content and icon.
+func MsgOptions(msg model.Message) ([]slack.MsgOption, error) {
+	content, err := NewContent(msg)
+	if err != nil {
+		return nil, err
+	}
+	options := content.MsgOptions()
+
+	if msg.Emoji != "" { // Emoji has higher priority than Icon
+		options = append(options, slack.MsgOptionIconEmoji(msg.Emoji))
+	} else if msg.Icon != "" {
+		options = append(options, slack.MsgOptionIconURL(msg.Icon))
+	}
+
+	return options, nil
+}
+
+// Attachment renders the message as an attachment with Block Kit blocks and color bar.
+func Attachment(msg model.Message) slack.Attachment {
+	var blockSet []slack.Block
+
+	if msg.Title != "" {
+		txt := slack.NewTextBlockObject("plain_text", msg.Title, false, false)
+		blockSet = append(blockSet, slack.NewHeaderBlock(txt))
+	}
+
+	var body *slack.TextBlockObject
+	if msg.Body != "" {
+		body = slack.NewTextBlockObject("mrkdwn", msg.Body, false, false)
+	}
+
+	fields := make([]*slack.TextBlockObject, len(msg.Fields))
+	for i, field := range msg.Fields {
+		value := field.Value
+		if field.Link != "" {
+			value = "<" + field.Link + "|" + field.Value + ">"
+		}
+		mrkdwn := fmt.Sprintf("*%s*\n%s", field.Name, value)
+		fields[i] = slack.NewTextBlockObject("mrkdwn", mrkdwn, false, false)
+	}
+
+	blockSet = append(blockSet, slack.NewSectionBlock(body, fields, nil))
+
+	return slack.Attachment{
+		Color: msg.ColorCode(),
+		Blocks: slack.Blocks{
+			BlockSet: blockSet,
+		},
+	}
+}
+
+// LegacyAttachment renders the message as an attachment with legacy fields (title, text and fields) instead of Block Kit. It is for Slack compatible services such as Mattermost and Rocket.Chat that do not support Block Kit. Links are written in Markdown.
+func LegacyAttachment(msg model.Message) slack.Attachment {
+	fields := make([]slack.AttachmentField, len(msg.Fields))
+	for i, field := range msg.Fields {
+		value := field.Value
+		if field.Link != "" {
+			value = "[" + field.Value + "](" + field.Link + ")"
+		}
+		fields[i] = slack.AttachmentField{
+			Title: field.Name,
+			Value: value,
+			Short: true,
+		}
+	}
+
+	fallback := msg.Title
+	if fallback == "" {
+		fallback = msg.Body
+	}
+
+	return slack.Attachment{
+		Color:    msg.ColorCode(),
+		Fallback: fallback,
+		Title:    msg.Title,
+		Text:     msg.Body,
+		Fields:   fields,
+	}
+}